@@ -0,0 +1,33 @@
+package v1alpha1
+
+import (
+	zkLogger "github.com/zerok-ai/zk-utils-go/logs"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// SetupWebhookWithManager registers the admission webhooks of ClusterZerokProbe with the manager.
+// The validating webhook only admits probes in the allowedNamespaces, every namespace is allowed when it is empty.
+func (r *ClusterZerokProbe) SetupWebhookWithManager(mgr ctrl.Manager, allowedNamespaces []string) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&ProbeValidator{AllowedNamespaces: allowedNamespaces}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-operator-zerok-ai-v1alpha1-clusterzerokprobe,mutating=true,failurePolicy=fail,sideEffects=None,groups=operator.zerok.ai,resources=clusterzerokprobes,verbs=create;update,versions=v1alpha1,name=mclusterzerokprobe.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &ClusterZerokProbe{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *ClusterZerokProbe) Default() {
+	zkLogger.Debug(zerokProbeWebhookLogTag, "Setting defaults of cluster probe ", r.Name)
+
+	// the spec of a probe being deleted is left as it is, so that removing the finalizer is not blocked
+	if !r.GetDeletionTimestamp().IsZero() {
+		return
+	}
+	SetZerokProbeSpecDefaults(&r.Spec, "")
+}
+
+//+kubebuilder:webhook:path=/validate-operator-zerok-ai-v1alpha1-clusterzerokprobe,mutating=false,failurePolicy=fail,sideEffects=None,groups=operator.zerok.ai,resources=clusterzerokprobes,verbs=create;update,versions=v1alpha1,name=vclusterzerokprobe.kb.io,admissionReviewVersions=v1
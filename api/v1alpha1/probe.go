@@ -0,0 +1,93 @@
+package v1alpha1
+
+import (
+	"context"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Kinds of the probes translated into scenarios.
+const (
+	ZerokProbeKind        = "ZerokProbe"
+	ClusterZerokProbeKind = "ClusterZerokProbe"
+)
+
+// Probe is implemented by the kinds whose spec is translated into a scenario, i.e. ZerokProbe and
+// ClusterZerokProbe. The spec and status are returned as pointers, changes to them are made on the probe.
+// +kubebuilder:object:generate=false
+type Probe interface {
+	client.Object
+	GetSpec() *ZerokProbeSpec
+	GetStatus() *ZerokProbeStatus
+	// GetProbeKind returns the kind of the probe, the TypeMeta of objects read through a client is not always set.
+	GetProbeKind() string
+}
+
+var _ Probe = &ZerokProbe{}
+var _ Probe = &ClusterZerokProbe{}
+
+func (r *ZerokProbe) GetSpec() *ZerokProbeSpec {
+	return &r.Spec
+}
+
+func (r *ZerokProbe) GetStatus() *ZerokProbeStatus {
+	return &r.Status
+}
+
+func (r *ZerokProbe) GetProbeKind() string {
+	return ZerokProbeKind
+}
+
+func (r *ClusterZerokProbe) GetSpec() *ZerokProbeSpec {
+	return &r.Spec
+}
+
+func (r *ClusterZerokProbe) GetStatus() *ZerokProbeStatus {
+	return &r.Status
+}
+
+func (r *ClusterZerokProbe) GetProbeKind() string {
+	return ClusterZerokProbeKind
+}
+
+// IsProbeNamespaceAllowed tells whether the probe may be live. Cluster scoped probes are not restricted by the
+// allowed namespaces.
+func IsProbeNamespaceAllowed(probe Probe, allowedNamespaces []string) bool {
+	return probe.GetNamespace() == "" || IsNamespaceAllowed(allowedNamespaces, probe.GetNamespace())
+}
+
+// ValidateProbeNamespace checks that probes may be created in the namespace of the probe. Cluster scoped probes
+// are not restricted by the allowed namespaces.
+func ValidateProbeNamespace(probe Probe, allowedNamespaces []string) field.ErrorList {
+	if probe.GetNamespace() == "" {
+		return nil
+	}
+	return ValidateZerokProbeNamespace(allowedNamespaces, probe.GetNamespace(), field.NewPath("metadata", "namespace"))
+}
+
+// ValidateProbe validates the namespace and the spec of a probe of either kind.
+func ValidateProbe(probe Probe, allowedNamespaces []string) field.ErrorList {
+	allErrs := ValidateProbeNamespace(probe, allowedNamespaces)
+	return append(allErrs, ValidateZerokProbeSpec(probe.GetSpec(), probe.GetNamespace(), field.NewPath("spec"))...)
+}
+
+// ListProbes lists the ZerokProbes of every namespace followed by the ClusterZerokProbes.
+func ListProbes(ctx context.Context, reader client.Reader) ([]Probe, error) {
+	zerokProbes := &ZerokProbeList{}
+	if err := reader.List(ctx, zerokProbes); err != nil {
+		return nil, err
+	}
+	clusterZerokProbes := &ClusterZerokProbeList{}
+	if err := reader.List(ctx, clusterZerokProbes); err != nil {
+		return nil, err
+	}
+
+	probes := make([]Probe, 0, len(zerokProbes.Items)+len(clusterZerokProbes.Items))
+	for i := range zerokProbes.Items {
+		probes = append(probes, &zerokProbes.Items[i])
+	}
+	for i := range clusterZerokProbes.Items {
+		probes = append(probes, &clusterZerokProbes.Items[i])
+	}
+	return probes, nil
+}
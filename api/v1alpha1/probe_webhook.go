@@ -0,0 +1,87 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	zkLogger "github.com/zerok-ai/zk-utils-go/logs"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// ProbeValidator validates ZerokProbes and ClusterZerokProbes in the validating webhooks. AllowedNamespaces are the
+// namespaces ZerokProbes may be created in, every namespace is allowed when it is empty.
+// +kubebuilder:object:generate=false
+type ProbeValidator struct {
+	AllowedNamespaces []string
+}
+
+var _ webhook.CustomValidator = &ProbeValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *ProbeValidator) ValidateCreate(_ context.Context, obj runtime.Object) error {
+	probe, err := toProbe(obj)
+	if err != nil {
+		return err
+	}
+	zkLogger.Debug(zerokProbeWebhookLogTag, "Validating create of ", probe.GetProbeKind(), " ", probe.GetName())
+	return v.validateProbe(probe)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *ProbeValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) error {
+	probe, err := toProbe(newObj)
+	if err != nil {
+		return err
+	}
+	zkLogger.Debug(zerokProbeWebhookLogTag, "Validating update of ", probe.GetProbeKind(), " ", probe.GetName())
+
+	// metadata only updates, like the operator adding or removing its finalizer, must not be blocked
+	// by a spec that was stored before the webhook was installed
+	oldProbe, err := toProbe(oldObj)
+	if !probe.GetDeletionTimestamp().IsZero() || (err == nil && equality.Semantic.DeepEqual(oldProbe.GetSpec(), probe.GetSpec())) {
+		return nil
+	}
+	if err == nil {
+		probe = wrapUnchangedRootRules(oldProbe, probe)
+	}
+	return v.validateProbe(probe)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *ProbeValidator) ValidateDelete(_ context.Context, _ runtime.Object) error {
+	return nil
+}
+
+func (v *ProbeValidator) validateProbe(probe Probe) error {
+	allErrs := ValidateProbe(probe, v.AllowedNamespaces)
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind(probe.GetProbeKind()).GroupKind(), probe.GetName(), allErrs)
+}
+
+// wrapUnchangedRootRules returns a copy of probe in which the single root rules that are unchanged from oldProbe are
+// wrapped in a group. Probes stored before the root of a rule had to be a rule_group can then still be updated as
+// long as such a rule is left as it is.
+func wrapUnchangedRootRules(oldProbe Probe, probe Probe) Probe {
+	wrapped := probe.DeepCopyObject().(Probe)
+	spec := wrapped.GetSpec()
+	for key, workload := range spec.Workloads {
+		oldWorkload, ok := oldProbe.GetSpec().Workloads[key]
+		if ok && equality.Semantic.DeepEqual(oldWorkload.Rule, workload.Rule) {
+			workload.Rule = WrapRootRule(workload.Rule)
+			spec.Workloads[key] = workload
+		}
+	}
+	return wrapped
+}
+
+func toProbe(obj runtime.Object) (Probe, error) {
+	probe, ok := obj.(Probe)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a ZerokProbe or a ClusterZerokProbe, got %T", obj))
+	}
+	return probe, nil
+}
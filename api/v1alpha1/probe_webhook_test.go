@@ -0,0 +1,81 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zerok-ai/zk-utils-go/scenario/model"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// newValidatorProbe returns a probe whose two rate limits share a tick, which the validator rejects.
+func newValidatorProbe(namespace string) *ZerokProbe {
+	return &ZerokProbe{
+		ObjectMeta: metav1.ObjectMeta{Name: "errors", Namespace: namespace},
+		Spec: ZerokProbeSpec{Title: "errors", Enabled: true, RateLimit: []RateLimit{
+			{BucketMaxSize: 5, BucketRefillSize: 5, TickDuration: "1m"},
+			{BucketMaxSize: 5, BucketRefillSize: 5, TickDuration: "60s"},
+		}},
+	}
+}
+
+func TestProbeValidator(t *testing.T) {
+	validProbe := newValidatorProbe("team-a")
+	validProbe.Spec.RateLimit = validProbe.Spec.RateLimit[:1]
+	invalidProbe := newValidatorProbe("team-a")
+	deletingProbe := newValidatorProbe("team-a")
+	now := metav1.Now()
+	deletingProbe.DeletionTimestamp = &now
+	// a probe stored before the root of a rule had to be a rule_group
+	legacyProbe := validProbe.DeepCopy()
+	id, datatype, operator, value := "http.status_code", model.DataType(DataTypeInteger), model.OperatorTypes(OperatorEqual), model.ValueTypes("404")
+	leafRule := model.Rule{Type: model.RULE, RuleLeaf: &model.RuleLeaf{ID: &id, Datatype: &datatype, Operator: &operator, Value: &value}}
+	legacyProbe.Spec.Workloads = Workloads{"OTEL/orders": {Rule: leafRule}}
+	retitledLegacyProbe := legacyProbe.DeepCopy()
+	retitledLegacyProbe.Spec.Title = "orders errors"
+	changedValue := model.ValueTypes("500")
+	changedLegacyProbe := legacyProbe.DeepCopy()
+	changedLegacyProbe.Spec.Workloads["OTEL/orders"].Rule.RuleLeaf.Value = &changedValue
+	clusterProbe := &ClusterZerokProbe{ObjectMeta: metav1.ObjectMeta{Name: "errors"}, Spec: validProbe.Spec}
+
+	tests := []struct {
+		name              string
+		allowedNamespaces []string
+		oldObj            runtime.Object
+		newObj            runtime.Object
+		valid             bool
+	}{
+		{name: "create valid probe", newObj: validProbe, valid: true},
+		{name: "create invalid probe", newObj: invalidProbe, valid: false},
+		{name: "create probe in an allowed namespace", allowedNamespaces: []string{"team-a"}, newObj: validProbe, valid: true},
+		{name: "create probe in another namespace", allowedNamespaces: []string{"team-b"}, newObj: validProbe, valid: false},
+		{name: "create cluster probe with allowed namespaces", allowedNamespaces: []string{"team-b"}, newObj: clusterProbe, valid: true},
+		{name: "update invalid probe with an unchanged spec", oldObj: invalidProbe, newObj: invalidProbe, valid: true},
+		{name: "update invalid probe being deleted", oldObj: validProbe, newObj: deletingProbe, valid: true},
+		{name: "update valid probe into an invalid one", oldObj: validProbe, newObj: invalidProbe, valid: false},
+		{name: "update probe of a namespace no longer allowed", allowedNamespaces: []string{"team-b"}, oldObj: invalidProbe, newObj: validProbe, valid: false},
+		{name: "create probe with a single rule at the root", newObj: legacyProbe, valid: false},
+		{name: "update probe keeping a single rule at the root", oldObj: legacyProbe, newObj: retitledLegacyProbe, valid: true},
+		{name: "update the single rule at the root", oldObj: legacyProbe, newObj: changedLegacyProbe, valid: false},
+		{name: "create object which is not a probe", newObj: &ZerokProbeTemplate{}, valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := &ProbeValidator{AllowedNamespaces: tt.allowedNamespaces}
+			var err error
+			if tt.oldObj == nil {
+				err = validator.ValidateCreate(context.Background(), tt.newObj)
+			} else {
+				err = validator.ValidateUpdate(context.Background(), tt.oldObj, tt.newObj)
+			}
+			if tt.valid && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.valid && !apierrors.IsInvalid(err) && !apierrors.IsBadRequest(err) {
+				t.Fatalf("expected the probe to be rejected, got %v", err)
+			}
+		})
+	}
+}
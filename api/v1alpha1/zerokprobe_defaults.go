@@ -0,0 +1,104 @@
+package v1alpha1
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Defaults applied to a probe when the corresponding fields are not set in the spec.
+const (
+	DefaultWorkloadScope    = WorkloadScopeCluster
+	DefaultTraceRole        = TraceRoleServer
+	DefaultProtocol         = ProtocolHTTP
+	DefaultBucketMaxSize    = 5
+	DefaultBucketRefillSize = 5
+	DefaultTickDuration     = "1m"
+)
+
+// SetZerokProbeSpecDefaults fills in the fields of the spec which are not set with the values the operator
+// uses while translating the probe into a scenario. namespace is the namespace of the probe, empty for a
+// ClusterZerokProbe.
+func SetZerokProbeSpecDefaults(spec *ZerokProbeSpec, namespace string) {
+	// the spec of a probe rendered from a template is defaulted once it is rendered
+	if spec.Template != nil {
+		return
+	}
+
+	if spec.WorkloadScope == "" {
+		spec.WorkloadScope = DefaultWorkloadScope
+	}
+
+	serviceNames := make([]string, 0, len(spec.Workloads))
+	for key, workload := range spec.Workloads {
+		if workload.TraceRole == "" {
+			workload.TraceRole = DefaultTraceRole
+		}
+		if workload.Protocol == "" {
+			workload.Protocol = DefaultProtocol
+		}
+
+		executor, serviceName, err := ParseWorkloadKey(key)
+		if err == nil {
+			serviceNames = append(serviceNames, serviceName)
+		}
+		if err == nil && executor == EBPF {
+			if workload.Namespace == "" {
+				workload.Namespace = namespace
+			}
+			if workload.Deployment == "" {
+				workload.Deployment = serviceName
+			}
+		}
+		if workload.Selector != nil && workload.Selector.Namespace == "" {
+			workload.Selector.Namespace = namespace
+		}
+		spec.Workloads[key] = workload
+	}
+	sort.Strings(serviceNames)
+
+	// a probe without a filter selects the traces which satisfy all of its workloads
+	if spec.Filter.WorkloadKeys == nil && spec.Filter.Filters == nil {
+		workloadKeys := WorkloadKeys(serviceNames)
+		spec.Filter.WorkloadKeys = &workloadKeys
+	}
+	setFilterDefaults(&spec.Filter)
+
+	if spec.RateLimit == nil {
+		spec.RateLimit = []RateLimit{
+			{BucketMaxSize: DefaultBucketMaxSize, BucketRefillSize: DefaultBucketRefillSize, TickDuration: DefaultTickDuration},
+		}
+	}
+	for i, rateLimit := range spec.RateLimit {
+		if tickDuration, err := time.ParseDuration(rateLimit.TickDuration); err == nil {
+			spec.RateLimit[i].TickDuration = NormalizeDuration(tickDuration)
+		}
+	}
+}
+
+// NormalizeDuration formats a duration in its shortest form, e.g. 1m instead of 60s or 1m0s, so that equal durations
+// are written the same way.
+func NormalizeDuration(duration time.Duration) string {
+	normalized := duration.String()
+	if strings.HasSuffix(normalized, "m0s") {
+		normalized = strings.TrimSuffix(normalized, "0s")
+	}
+	if strings.HasSuffix(normalized, "h0m") {
+		normalized = strings.TrimSuffix(normalized, "0m")
+	}
+	return normalized
+}
+
+func setFilterDefaults(filter *Filter) {
+	if filter.Type == "" {
+		filter.Type = FilterTypeWorkload
+	}
+	if filter.Condition == "" {
+		filter.Condition = AND
+	}
+	if filter.Filters != nil {
+		for i := range *filter.Filters {
+			setFilterDefaults(&(*filter.Filters)[i])
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/yaml"
+)
+
+func TestSetZerokProbeSpecDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		// namespace of the probe, empty for a ClusterZerokProbe
+		namespace string
+		spec      string
+		expected  string
+	}{
+		{name: "empty spec", spec: `{}`, expected: `
+workload_scope: Cluster
+filter:
+  type: workload
+  condition: AND
+  workload_keys: []
+rate_limit:
+  - {bucket_max_size: 5, bucket_refill_size: 5, tick_duration: 1m}
+`},
+		{name: "workloads", namespace: "team-a", spec: `
+workloads:
+  OTEL/orders:
+    rule: ` + statusRule + `
+  OTEL/cart:
+    trace_role: client
+    protocol: GRPC
+    selector:
+      deployment: cart
+    rule: ` + statusRule + `
+  EBPF/payments:
+    rule: ` + statusRule + `
+  EBPF/shipping:
+    namespace: logistics
+    deployment: shipping-v2
+    rule: ` + statusRule + `
+`, expected: `
+workload_scope: Cluster
+workloads:
+  OTEL/orders:
+    trace_role: server
+    protocol: HTTP
+    rule: ` + statusRule + `
+  OTEL/cart:
+    trace_role: client
+    protocol: GRPC
+    selector:
+      namespace: team-a
+      deployment: cart
+    rule: ` + statusRule + `
+  EBPF/payments:
+    trace_role: server
+    protocol: HTTP
+    namespace: team-a
+    deployment: payments
+    rule: ` + statusRule + `
+  EBPF/shipping:
+    trace_role: server
+    protocol: HTTP
+    namespace: logistics
+    deployment: shipping-v2
+    rule: ` + statusRule + `
+filter:
+  type: workload
+  condition: AND
+  workload_keys: [cart, orders, payments, shipping]
+rate_limit:
+  - {bucket_max_size: 5, bucket_refill_size: 5, tick_duration: 1m}
+`},
+		{name: "filter", spec: `
+workload_scope: Namespace
+filter:
+  type: ""
+  condition: ""
+  workload_keys: [orders]
+  filters:
+    - {type: "", condition: OR, workload_keys: [cart, payments]}
+rate_limit: []
+`, expected: `
+workload_scope: Namespace
+filter:
+  type: workload
+  condition: AND
+  workload_keys: [orders]
+  filters:
+    - {type: workload, condition: OR, workload_keys: [cart, payments]}
+rate_limit: []
+`},
+		{name: "rate limits are normalized", spec: `
+rate_limit:
+  - {bucket_max_size: 10, bucket_refill_size: 10, tick_duration: 60s}
+  - {bucket_max_size: 100, bucket_refill_size: 100, tick_duration: 90m}
+  - {bucket_max_size: 100, bucket_refill_size: 100, tick_duration: 1 hour}
+`, expected: `
+workload_scope: Cluster
+filter:
+  type: workload
+  condition: AND
+  workload_keys: []
+rate_limit:
+  - {bucket_max_size: 10, bucket_refill_size: 10, tick_duration: 1m}
+  - {bucket_max_size: 100, bucket_refill_size: 100, tick_duration: 1h30m}
+  - {bucket_max_size: 100, bucket_refill_size: 100, tick_duration: 1 hour}
+`},
+		{name: "probe rendered from a template", spec: `
+template:
+  name: errors
+`, expected: `
+template:
+  name: errors
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &ZerokProbeSpec{}
+			if err := yaml.UnmarshalStrict([]byte(tt.spec), spec); err != nil {
+				t.Fatalf("invalid spec: %v", err)
+			}
+			expected := &ZerokProbeSpec{}
+			if err := yaml.UnmarshalStrict([]byte(tt.expected), expected); err != nil {
+				t.Fatalf("invalid expected spec: %v", err)
+			}
+
+			SetZerokProbeSpecDefaults(spec, tt.namespace)
+			if !equality.Semantic.DeepEqual(spec, expected) {
+				t.Fatalf("unexpected defaults\n got: %+v\nwant: %+v", spec, expected)
+			}
+
+			// the webhook may default a probe which was defaulted before
+			SetZerokProbeSpecDefaults(spec, tt.namespace)
+			if !equality.Semantic.DeepEqual(spec, expected) {
+				t.Fatalf("defaults changed when set again\n got: %+v\nwant: %+v", spec, expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeDuration(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		expected string
+	}{
+		{duration: 30 * time.Second, expected: "30s"},
+		{duration: 60 * time.Second, expected: "1m"},
+		{duration: 90 * time.Second, expected: "1m30s"},
+		{duration: time.Hour, expected: "1h"},
+		{duration: 90 * time.Minute, expected: "1h30m"},
+		{duration: time.Hour + time.Second, expected: "1h0m1s"},
+		{duration: 24 * time.Hour, expected: "24h"},
+		{duration: 500 * time.Millisecond, expected: "500ms"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if normalized := NormalizeDuration(tt.duration); normalized != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, normalized)
+			}
+		})
+	}
+}
@@ -11,6 +11,8 @@ type OperatorTypes string
 type ValueTypes string
 type ProtocolName string
 type ExecutorName string
+type TraceRole string
+type WorkloadScope string
 
 type ExecutorTypeEnum struct {
 	OTEL ExecutorType
@@ -19,6 +21,7 @@ type ExecutorTypeEnum struct {
 
 const (
 	OTEL ExecutorType = "OTEL"
+	EBPF ExecutorType = "EBPF"
 )
 
 type ExecutorType string
@@ -28,24 +31,80 @@ type Workloads map[string]Workload
 
 // +k8s:deepcopy-gen=true
 type ZerokProbeSpec struct {
-	Title     string      `json:"title"`
-	Enabled   bool        `json:"enabled"`
-	Workloads Workloads   `json:"workloads,omitempty"`
-	Filter    Filter      `json:"filter,omitempty"`
-	GroupBy   []GroupBy   `json:"group_by,omitempty"`
-	RateLimit []RateLimit `json:"rate_limit,omitempty"`
+	Title   string `json:"title"`
+	Enabled bool   `json:"enabled"`
+	// WorkloadScope limits the workloads of the probe to the namespace of the probe when set to Namespace.
+	// Defaults to Cluster, where the workloads match services of any namespace.
+	// +kubebuilder:validation:Enum=Cluster;Namespace
+	WorkloadScope WorkloadScope `json:"workload_scope,omitempty"`
+	// Template renders the workloads, filter, group_by, rate_limit and workload_scope of the probe from a
+	// ZerokProbeTemplate, they must not be set on the probe itself.
+	Template  *ZerokProbeTemplateRef `json:"template,omitempty"`
+	Workloads Workloads              `json:"workloads,omitempty"`
+	Filter    Filter                 `json:"filter,omitempty"`
+	GroupBy   []GroupBy              `json:"group_by,omitempty"`
+	RateLimit []RateLimit            `json:"rate_limit,omitempty"`
+	// ActiveFrom is the time from which the scenario of the probe is stored, it is stored right away when unset.
+	ActiveFrom *metav1.Time `json:"active_from,omitempty"`
+	// ActiveUntil is the time from which the scenario of the probe is no longer stored, it is kept when unset.
+	ActiveUntil *metav1.Time `json:"active_until,omitempty"`
+	// TTL is how long after its creation the probe is deleted, e.g. 24h. The probe is kept when unset.
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+	// Schedule activates the probe on a recurring schedule, within active_from and active_until.
+	Schedule *ZerokProbeSchedule `json:"schedule,omitempty"`
+}
+
+// ZerokProbeSchedule activates a probe for Duration every time Cron fires.
+// +k8s:deepcopy-gen=true
+type ZerokProbeSchedule struct {
+	// Cron is a cron expression with five fields, e.g. "0 9 * * 1-5", or a descriptor like @daily. It is evaluated
+	// in UTC unless it starts with CRON_TZ=<time zone>.
+	Cron string `json:"cron"`
+	// Duration is how long the probe stays active every time Cron fires, e.g. 8h.
+	Duration metav1.Duration `json:"duration"`
 }
 
 // +k8s:deepcopy-gen=true
 type Workload struct {
 	Rule model.Rule `json:"rule,omitempty"`
+	// TraceRole is the role of the span in the trace. Defaults to server.
+	// +kubebuilder:validation:Enum=server;client;producer;consumer
+	TraceRole TraceRole `json:"trace_role,omitempty"`
+	// Protocol of the span. Defaults to HTTP.
+	// +kubebuilder:validation:Enum=HTTP;GRPC;MYSQL;POSTGRESQL;REDIS;KAFKA;GENERAL;IDENTIFIER
+	Protocol ProtocolName `json:"protocol,omitempty"`
+	// Namespace of the workload, only used by the EBPF executor. Defaults to the namespace of the probe.
+	Namespace string `json:"namespace,omitempty"`
+	// Deployment is the name of the workload, only used by the EBPF executor. Defaults to the name in the workload key.
+	Deployment string `json:"deployment,omitempty"`
+	// Selector resolves the services of an OTEL workload from the pods it selects, the name in the workload key is
+	// then only used to refer to the workload in the filter and group_by.
+	Selector *WorkloadSelector `json:"selector,omitempty"`
+}
+
+// WorkloadSelector selects the pods of an OTEL workload, the operator resolves it into the OTel service names the pods
+// report in their OTEL_SERVICE_NAME or OTEL_RESOURCE_ATTRIBUTES env. Exactly one of PodSelector and Deployment is set.
+// +k8s:deepcopy-gen=true
+type WorkloadSelector struct {
+	// Namespace of the pods. Defaults to the namespace of the probe.
+	Namespace string `json:"namespace,omitempty"`
+	// PodSelector selects the pods by their labels.
+	PodSelector *metav1.LabelSelector `json:"pod_selector,omitempty"`
+	// Deployment selects the pods of the Deployment with the name.
+	Deployment string `json:"deployment,omitempty"`
 }
 
+// RateLimit is a token bucket which holds up to BucketMaxSize traces and gets BucketRefillSize traces back every
+// TickDuration.
 // +k8s:deepcopy-gen=true
 type RateLimit struct {
-	BucketMaxSize    int    `json:"bucket_max_size"`
-	BucketRefillSize int    `json:"bucket_refill_size"`
-	TickDuration     string `json:"tick_duration"`
+	// +kubebuilder:validation:Minimum=1
+	BucketMaxSize int `json:"bucket_max_size"`
+	// BucketRefillSize must not be greater than BucketMaxSize.
+	// +kubebuilder:validation:Minimum=1
+	BucketRefillSize int `json:"bucket_refill_size"`
+	// TickDuration is a duration like 1m or 30s, it is normalized to its shortest form, e.g. 60s to 1m.
+	TickDuration string `json:"tick_duration"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -129,18 +188,60 @@ const (
 	ProbeDeleting ZerokProbePhase = "Deleting"
 )
 
+// ZerokProbeConditionType is a valid value for the type of a ZerokProbe condition.
+type ZerokProbeConditionType string
+
+// These are the valid condition types of a probe.
+const (
+	// ProbeValidated means the probe spec has been validated and translated into a scenario.
+	ProbeValidated ZerokProbeConditionType = "Validated"
+	// ProbeStoredInRedis means the translated scenario has been written to redis.
+	ProbeStoredInRedis ZerokProbeConditionType = "StoredInRedis"
+	// ProbeReady means the probe is live and traces are being filtered with it.
+	ProbeReady ZerokProbeConditionType = "Ready"
+	// ProbeActive means the current time is within the active window of the probe and its workload selectors
+	// select pods.
+	ProbeActive ZerokProbeConditionType = "Active"
+)
+
 // ZerokProbeStatus defines the observed state of Probe
 type ZerokProbeStatus struct {
 
 	// +optional
 	Phase ZerokProbePhase `json:"phase,omitempty"`
 
+	// ObservedGeneration is the most recent generation of the probe processed by the operator.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ScenarioHash is the hash of the content of the scenario last stored for the probe, empty when no scenario is stored.
+	// +optional
+	ScenarioHash string `json:"scenarioHash,omitempty"`
+
+	// ExpiresAt is the time the probe is deleted at, set for a probe with a ttl.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// NextActivation is the next time the schedule of the probe activates it.
+	// +optional
+	NextActivation *metav1.Time `json:"nextActivation,omitempty"`
+
+	// NextDeactivation is the next time the schedule of the probe deactivates it.
+	// +optional
+	NextDeactivation *metav1.Time `json:"nextDeactivation,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Title",type=string,JSONPath=`.spec.title`
+// +kubebuilder:printcolumn:name="Enabled",type=boolean,JSONPath=`.spec.enabled`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // ZerokProbe is used to specify rules to filter the spans generated by the services
 // ZerokProbe is the CRD schema for crating probe
 type ZerokProbe struct {
@@ -0,0 +1,548 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zerok-ai/zk-utils-go/scenario/model"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	FilterTypeWorkload = "workload"
+	FilterTypeFilter   = "filter"
+)
+
+const (
+	TraceRoleServer   TraceRole = "server"
+	TraceRoleClient   TraceRole = "client"
+	TraceRoleProducer TraceRole = "producer"
+	TraceRoleConsumer TraceRole = "consumer"
+)
+
+const (
+	WorkloadScopeCluster   WorkloadScope = "Cluster"
+	WorkloadScopeNamespace WorkloadScope = "Namespace"
+)
+
+const (
+	ProtocolHTTP       ProtocolName = "HTTP"
+	ProtocolGRPC       ProtocolName = "GRPC"
+	ProtocolMySQL      ProtocolName = "MYSQL"
+	ProtocolPostgreSQL ProtocolName = "POSTGRESQL"
+	ProtocolRedis      ProtocolName = "REDIS"
+	ProtocolKafka      ProtocolName = "KAFKA"
+	ProtocolGeneral    ProtocolName = "GENERAL"
+	ProtocolIdentifier ProtocolName = "IDENTIFIER"
+)
+
+const (
+	DataTypeString  DataType = "string"
+	DataTypeInteger DataType = "integer"
+	DataTypeFloat   DataType = "float"
+	DataTypeBool    DataType = "bool"
+)
+
+const (
+	OperatorExists           OperatorTypes = "exists"
+	OperatorNotExists        OperatorTypes = "not_exists"
+	OperatorMatches          OperatorTypes = "matches"
+	OperatorDoesNotMatch     OperatorTypes = "does_not_match"
+	OperatorEqual            OperatorTypes = "equal"
+	OperatorNotEqual         OperatorTypes = "not_equal"
+	OperatorContains         OperatorTypes = "contains"
+	OperatorDoesNotContain   OperatorTypes = "does_not_contain"
+	OperatorIn               OperatorTypes = "in"
+	OperatorNotIn            OperatorTypes = "not_in"
+	OperatorBeginsWith       OperatorTypes = "begins_with"
+	OperatorDoesNotBeginWith OperatorTypes = "does_not_begin_with"
+	OperatorEndsWith         OperatorTypes = "ends_with"
+	OperatorDoesNotEndWith   OperatorTypes = "does_not_end_with"
+	OperatorLessThan         OperatorTypes = "less_than"
+	OperatorLessThanEqual    OperatorTypes = "less_than_equal"
+	OperatorGreaterThan      OperatorTypes = "greater_than"
+	OperatorGreaterThanEqual OperatorTypes = "greater_than_equal"
+	OperatorBetween          OperatorTypes = "between"
+	OperatorNotBetween       OperatorTypes = "not_between"
+)
+
+var numericOperators = []OperatorTypes{
+	OperatorExists, OperatorNotExists, OperatorLessThan, OperatorLessThanEqual, OperatorGreaterThan,
+	OperatorGreaterThanEqual, OperatorEqual, OperatorNotEqual, OperatorBetween, OperatorNotBetween,
+	OperatorIn, OperatorNotIn,
+}
+
+// SupportedOperators lists the operators applicable to each data type, as documented in ZEROKPROBE.md.
+var SupportedOperators = map[DataType][]OperatorTypes{
+	DataTypeString: {
+		OperatorExists, OperatorNotExists, OperatorMatches, OperatorDoesNotMatch, OperatorEqual, OperatorNotEqual,
+		OperatorContains, OperatorDoesNotContain, OperatorIn, OperatorNotIn, OperatorBeginsWith,
+		OperatorDoesNotBeginWith, OperatorEndsWith, OperatorDoesNotEndWith,
+	},
+	DataTypeInteger: numericOperators,
+	DataTypeFloat:   numericOperators,
+	DataTypeBool:    {OperatorExists, OperatorNotExists},
+}
+
+var supportedExecutors = []ExecutorType{OTEL, EBPF}
+
+// SupportedTraceRoles lists the values accepted in the trace_role of a workload.
+var SupportedTraceRoles = []TraceRole{TraceRoleServer, TraceRoleClient, TraceRoleProducer, TraceRoleConsumer}
+
+// SupportedProtocols lists the values accepted in the protocol of a workload.
+var SupportedProtocols = []ProtocolName{
+	ProtocolHTTP, ProtocolGRPC, ProtocolMySQL, ProtocolPostgreSQL, ProtocolRedis, ProtocolKafka, ProtocolGeneral,
+	ProtocolIdentifier,
+}
+
+// SupportedWorkloadScopes lists the values accepted in the workload_scope of a probe.
+var SupportedWorkloadScopes = []WorkloadScope{WorkloadScopeCluster, WorkloadScopeNamespace}
+
+// IsNamespaceAllowed tells whether probes may be created in the namespace, every namespace is allowed when
+// allowedNamespaces is empty.
+func IsNamespaceAllowed(allowedNamespaces []string, namespace string) bool {
+	return len(allowedNamespaces) == 0 || slices.Contains(allowedNamespaces, namespace)
+}
+
+// ValidateZerokProbeNamespace checks that probes may be created in the namespace of the probe.
+func ValidateZerokProbeNamespace(allowedNamespaces []string, namespace string, fldPath *field.Path) field.ErrorList {
+	if IsNamespaceAllowed(allowedNamespaces, namespace) {
+		return nil
+	}
+	return field.ErrorList{field.Forbidden(fldPath, fmt.Sprintf("probes are not allowed in namespace %q", namespace))}
+}
+
+// ParseWorkloadKey splits a workload key of the form `<executor>/<service name>` into its parts.
+func ParseWorkloadKey(workloadKey string) (ExecutorType, string, error) {
+	parts := strings.Split(workloadKey, "/")
+
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid workload key %q, expected <executor>/<service name>", workloadKey)
+	}
+
+	executor := ExecutorType(parts[0])
+	if !slices.Contains(supportedExecutors, executor) {
+		return "", "", fmt.Errorf("invalid executor:%s provided in workload key", executor)
+	}
+
+	return executor, parts[1], nil
+}
+
+// ValidateZerokProbeSpec validates the spec of a probe and returns the errors with the path of the offending fields.
+// namespace is the namespace of the probe, empty for a ClusterZerokProbe.
+func ValidateZerokProbeSpec(spec *ZerokProbeSpec, namespace string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if spec.WorkloadScope != "" && !slices.Contains(SupportedWorkloadScopes, spec.WorkloadScope) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("workload_scope"), spec.WorkloadScope,
+			[]string{string(WorkloadScopeCluster), string(WorkloadScopeNamespace)}))
+	}
+	if spec.WorkloadScope == WorkloadScopeNamespace && namespace == "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("workload_scope"), "a cluster scoped probe has no namespace to limit its workloads to"))
+	}
+	if spec.Template != nil {
+		allErrs = append(allErrs, validateTemplateRef(spec, fldPath)...)
+	}
+	allErrs = append(allErrs, validateActiveWindow(spec, fldPath)...)
+
+	// walk the workloads in a stable order so that the errors are reported in the same order every time
+	workloadKeys := make([]string, 0, len(spec.Workloads))
+	for key := range spec.Workloads {
+		workloadKeys = append(workloadKeys, key)
+	}
+	sort.Strings(workloadKeys)
+
+	serviceNames := map[string]bool{}
+	workloadsPath := fldPath.Child("workloads")
+	for _, key := range workloadKeys {
+		workload := spec.Workloads[key]
+		workloadPath := workloadsPath.Key(key)
+		executor, serviceName, err := ParseWorkloadKey(key)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(workloadPath, key, err.Error()))
+			continue
+		}
+		// filters and group_by refer to workloads by name, so the name must be unique across executors
+		if serviceNames[serviceName] {
+			allErrs = append(allErrs, field.Duplicate(workloadPath, serviceName))
+		}
+		serviceNames[serviceName] = true
+		allErrs = append(allErrs, validateWorkloadIdentity(executor, workload, workloadPath)...)
+		if workload.Selector != nil {
+			allErrs = append(allErrs, validateWorkloadSelector(executor, workload.Selector, spec.WorkloadScope, namespace, workloadPath.Child("selector"))...)
+		}
+		// a namespace scoped probe can not reach into the workloads of other namespaces
+		if executor == EBPF && spec.WorkloadScope == WorkloadScopeNamespace && workload.Namespace != "" && workload.Namespace != namespace {
+			allErrs = append(allErrs, field.Invalid(workloadPath.Child("namespace"), workload.Namespace,
+				"must be the namespace of the probe when workload_scope is Namespace"))
+		}
+		allErrs = append(allErrs, validateWorkloadSpan(workload, workloadPath)...)
+		// the workload id is computed from the rules of the root group, a single rule has to be wrapped in a group
+		if workload.Rule.Type != model.RULE_GROUP {
+			allErrs = append(allErrs, field.NotSupported(workloadPath.Child("rule", "type"), workload.Rule.Type, []string{model.RULE_GROUP}))
+			continue
+		}
+		allErrs = append(allErrs, validateRule(workload.Rule, workloadPath.Child("rule"))...)
+	}
+
+	allErrs = append(allErrs, validateFilter(spec.Filter, serviceNames, fldPath.Child("filter"))...)
+
+	for i, groupBy := range spec.GroupBy {
+		if !serviceNames[groupBy.WorkloadKey] {
+			allErrs = append(allErrs, field.NotFound(fldPath.Child("group_by").Index(i).Child("workload_key"), groupBy.WorkloadKey))
+		}
+	}
+
+	allErrs = append(allErrs, validateRateLimits(spec.RateLimit, fldPath.Child("rate_limit"))...)
+
+	return allErrs
+}
+
+// validateRateLimits checks the bucket sizes and the tick duration of every rate limit. Two rate limits whose tick
+// durations are the same length of time, e.g. 60s and 1m, are rejected, it would be ambiguous which of them caps the
+// traces. Rate limits on different ticks are allowed together.
+func validateRateLimits(rateLimits []RateLimit, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	tickDurations := map[time.Duration]bool{}
+	for i, rateLimit := range rateLimits {
+		rateLimitPath := fldPath.Index(i)
+		if rateLimit.BucketMaxSize < 1 {
+			allErrs = append(allErrs, field.Invalid(rateLimitPath.Child("bucket_max_size"), rateLimit.BucketMaxSize, "must be greater than 0"))
+		}
+		if rateLimit.BucketRefillSize < 1 {
+			allErrs = append(allErrs, field.Invalid(rateLimitPath.Child("bucket_refill_size"), rateLimit.BucketRefillSize, "must be greater than 0"))
+		} else if rateLimit.BucketRefillSize > rateLimit.BucketMaxSize && rateLimit.BucketMaxSize >= 1 {
+			allErrs = append(allErrs, field.Invalid(rateLimitPath.Child("bucket_refill_size"), rateLimit.BucketRefillSize, "must not be greater than bucket_max_size"))
+		}
+
+		tickDurationPath := rateLimitPath.Child("tick_duration")
+		tickDuration, err := time.ParseDuration(rateLimit.TickDuration)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(tickDurationPath, rateLimit.TickDuration, err.Error()))
+			continue
+		}
+		if tickDuration <= 0 {
+			allErrs = append(allErrs, field.Invalid(tickDurationPath, rateLimit.TickDuration, "must be greater than 0"))
+			continue
+		}
+		if tickDurations[tickDuration] {
+			allErrs = append(allErrs, field.Duplicate(tickDurationPath, rateLimit.TickDuration))
+		}
+		tickDurations[tickDuration] = true
+	}
+	return allErrs
+}
+
+// validateTemplateRef checks that a probe rendered from a template leaves the rendered fields to the template.
+func validateTemplateRef(spec *ZerokProbeSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if spec.Template.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("template", "name"), ""))
+	}
+	const renderedMsg = "is rendered from spec.template"
+	if spec.WorkloadScope != "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("workload_scope"), renderedMsg))
+	}
+	if len(spec.Workloads) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("workloads"), renderedMsg))
+	}
+	if spec.Filter.Type != "" || spec.Filter.Condition != "" || spec.Filter.Filters != nil || spec.Filter.WorkloadKeys != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("filter"), renderedMsg))
+	}
+	if len(spec.GroupBy) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("group_by"), renderedMsg))
+	}
+	if len(spec.RateLimit) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("rate_limit"), renderedMsg))
+	}
+	return allErrs
+}
+
+// validateActiveWindow checks that the active window of a probe is not empty, that its ttl is positive and that its
+// schedule can be parsed.
+func validateActiveWindow(spec *ZerokProbeSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if spec.ActiveFrom != nil && spec.ActiveUntil != nil && !spec.ActiveUntil.After(spec.ActiveFrom.Time) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("active_until"), spec.ActiveUntil.Format(time.RFC3339),
+			"must be after active_from"))
+	}
+	if spec.TTL != nil && spec.TTL.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("ttl"), spec.TTL.Duration.String(), "must be greater than 0"))
+	}
+	if spec.Schedule != nil {
+		schedulePath := fldPath.Child("schedule")
+		if spec.Schedule.Cron == "" {
+			allErrs = append(allErrs, field.Required(schedulePath.Child("cron"), ""))
+		} else if _, err := ParseSchedule(spec.Schedule.Cron); err != nil {
+			allErrs = append(allErrs, field.Invalid(schedulePath.Child("cron"), spec.Schedule.Cron, err.Error()))
+		}
+		if spec.Schedule.Duration.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(schedulePath.Child("duration"), spec.Schedule.Duration.Duration.String(), "must be greater than 0"))
+		}
+	}
+	return allErrs
+}
+
+// validateWorkloadIdentity checks that the namespace and deployment of a workload are set only for, and always for,
+// the EBPF executor. They are filled in by SetZerokProbeSpecDefaults when left out.
+func validateWorkloadIdentity(executor ExecutorType, workload Workload, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if executor != EBPF {
+		if workload.Namespace != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("namespace"), "only supported for the EBPF executor"))
+		}
+		if workload.Deployment != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("deployment"), "only supported for the EBPF executor"))
+		}
+		return allErrs
+	}
+
+	if workload.Namespace == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("namespace"), ""))
+	} else {
+		for _, msg := range validation.IsDNS1123Label(workload.Namespace) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("namespace"), workload.Namespace, msg))
+		}
+	}
+	if workload.Deployment == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("deployment"), ""))
+	} else {
+		for _, msg := range validation.IsDNS1123Subdomain(workload.Deployment) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("deployment"), workload.Deployment, msg))
+		}
+	}
+	return allErrs
+}
+
+// validateWorkloadSelector checks that a selector is only set on an OTEL workload, selects the pods by exactly one of
+// its labels or a Deployment, and stays within the namespace of a namespace scoped probe.
+func validateWorkloadSelector(executor ExecutorType, selector *WorkloadSelector, workloadScope WorkloadScope, namespace string, fldPath *field.Path) field.ErrorList {
+	if executor != OTEL {
+		return field.ErrorList{field.Forbidden(fldPath, "only supported for the OTEL executor")}
+	}
+	allErrs := field.ErrorList{}
+
+	if selector.Namespace == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("namespace"), ""))
+	} else {
+		for _, msg := range validation.IsDNS1123Label(selector.Namespace) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("namespace"), selector.Namespace, msg))
+		}
+		if workloadScope == WorkloadScopeNamespace && selector.Namespace != namespace {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("namespace"), selector.Namespace,
+				"must be the namespace of the probe when workload_scope is Namespace"))
+		}
+	}
+
+	switch {
+	case selector.PodSelector == nil && selector.Deployment == "":
+		allErrs = append(allErrs, field.Required(fldPath, "one of pod_selector and deployment must be set"))
+	case selector.PodSelector != nil && selector.Deployment != "":
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("deployment"), "may not be set together with pod_selector"))
+	case selector.PodSelector != nil:
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(selector.PodSelector, metav1validation.LabelSelectorValidationOptions{}, fldPath.Child("pod_selector"))...)
+		if len(selector.PodSelector.MatchLabels) == 0 && len(selector.PodSelector.MatchExpressions) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("pod_selector"), "an empty selector would select every pod of the namespace"))
+		}
+	default:
+		for _, msg := range validation.IsDNS1123Subdomain(selector.Deployment) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("deployment"), selector.Deployment, msg))
+		}
+	}
+	return allErrs
+}
+
+// validateWorkloadSpan checks the trace role and protocol of a workload, empty values are filled in by
+// SetZerokProbeSpecDefaults.
+func validateWorkloadSpan(workload Workload, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if workload.TraceRole != "" && !slices.Contains(SupportedTraceRoles, workload.TraceRole) {
+		supportedTraceRoles := make([]string, 0, len(SupportedTraceRoles))
+		for _, traceRole := range SupportedTraceRoles {
+			supportedTraceRoles = append(supportedTraceRoles, string(traceRole))
+		}
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("trace_role"), workload.TraceRole, supportedTraceRoles))
+	}
+	if workload.Protocol != "" && !slices.Contains(SupportedProtocols, workload.Protocol) {
+		supportedProtocols := make([]string, 0, len(SupportedProtocols))
+		for _, protocol := range SupportedProtocols {
+			supportedProtocols = append(supportedProtocols, string(protocol))
+		}
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("protocol"), workload.Protocol, supportedProtocols))
+	}
+	return allErrs
+}
+
+func validateFilter(filter Filter, serviceNames map[string]bool, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if filter.Type != "" && filter.Type != FilterTypeWorkload && filter.Type != FilterTypeFilter {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), filter.Type, []string{FilterTypeWorkload, FilterTypeFilter}))
+	}
+	if filter.Condition != "" && filter.Condition != AND && filter.Condition != OR {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("condition"), filter.Condition, []string{string(AND), string(OR)}))
+	}
+
+	if filter.WorkloadKeys != nil {
+		for i, workloadKey := range *filter.WorkloadKeys {
+			if !serviceNames[workloadKey] {
+				allErrs = append(allErrs, field.NotFound(fldPath.Child("workload_keys").Index(i), workloadKey))
+			}
+		}
+	}
+
+	if filter.Filters != nil {
+		for i, nestedFilter := range *filter.Filters {
+			allErrs = append(allErrs, validateFilter(nestedFilter, serviceNames, fldPath.Child("filters").Index(i))...)
+		}
+	}
+
+	return allErrs
+}
+
+// WrapRootRule returns rule wrapped in an AND group of its own when it is a single rule. Probes stored before the
+// root of a rule had to be a rule_group are translated with their rule wrapped, which matches the same spans.
+func WrapRootRule(rule model.Rule) model.Rule {
+	if rule.Type != model.RULE {
+		return rule
+	}
+	condition := model.AND
+	return model.Rule{Type: model.RULE_GROUP, RuleGroup: &model.RuleGroup{Condition: &condition, Rules: model.Rules{*rule.DeepCopy()}}}
+}
+
+func validateRule(rule model.Rule, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	switch rule.Type {
+	case model.RULE_GROUP:
+		if rule.RuleGroup == nil || rule.RuleGroup.Condition == nil {
+			return append(allErrs, field.Required(fldPath.Child("condition"), "rule group must have a condition"))
+		}
+		condition := Condition(*rule.RuleGroup.Condition)
+		if condition != AND && condition != OR {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("condition"), condition, []string{string(AND), string(OR)}))
+		}
+		if len(rule.RuleGroup.Rules) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("rules"), "rule group must have at least one rule"))
+		}
+		for i, childRule := range rule.RuleGroup.Rules {
+			allErrs = append(allErrs, validateRule(childRule, fldPath.Child("rules").Index(i))...)
+		}
+	case model.RULE:
+		allErrs = append(allErrs, validateRuleLeaf(rule.RuleLeaf, fldPath)...)
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), rule.Type, []string{model.RULE, model.RULE_GROUP}))
+	}
+
+	return allErrs
+}
+
+func validateRuleLeaf(leaf *model.RuleLeaf, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if leaf == nil || leaf.ID == nil || *leaf.ID == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("id"), ""))
+	}
+	if leaf == nil || leaf.Datatype == nil {
+		return append(allErrs, field.Required(fldPath.Child("datatype"), ""))
+	}
+	if leaf.Operator == nil {
+		return append(allErrs, field.Required(fldPath.Child("operator"), ""))
+	}
+
+	dataType := DataType(*leaf.Datatype)
+	operator := OperatorTypes(*leaf.Operator)
+
+	operators, ok := SupportedOperators[dataType]
+	if !ok {
+		supportedDataTypes := []string{string(DataTypeString), string(DataTypeInteger), string(DataTypeFloat), string(DataTypeBool)}
+		return append(allErrs, field.NotSupported(fldPath.Child("datatype"), dataType, supportedDataTypes))
+	}
+	if !slices.Contains(operators, operator) {
+		supportedOperators := make([]string, 0, len(operators))
+		for _, supportedOperator := range operators {
+			supportedOperators = append(supportedOperators, string(supportedOperator))
+		}
+		return append(allErrs, field.NotSupported(fldPath.Child("operator"), operator, supportedOperators))
+	}
+
+	// exists and not_exists do not need a value
+	if operator == OperatorExists || operator == OperatorNotExists {
+		return allErrs
+	}
+
+	valuePath := fldPath.Child("value")
+	if leaf.Value == nil {
+		return append(allErrs, field.Required(valuePath, fmt.Sprintf("operator %s needs a value", operator)))
+	}
+	value := string(*leaf.Value)
+
+	switch operator {
+	case OperatorMatches, OperatorDoesNotMatch:
+		if _, err := regexp.Compile(value); err != nil {
+			allErrs = append(allErrs, field.Invalid(valuePath, value, err.Error()))
+		}
+	case OperatorBetween, OperatorNotBetween:
+		allErrs = append(allErrs, validateRangeValue(dataType, value, valuePath)...)
+	case OperatorIn, OperatorNotIn:
+		for _, item := range strings.Split(value, ",") {
+			if err := validateScalarValue(dataType, item); err != nil {
+				allErrs = append(allErrs, field.Invalid(valuePath, value, err.Error()))
+				break
+			}
+		}
+	default:
+		if err := validateScalarValue(dataType, value); err != nil {
+			allErrs = append(allErrs, field.Invalid(valuePath, value, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+// validateRangeValue checks that the value of a between operator is two comma separated numbers with start <= end.
+func validateRangeValue(dataType DataType, value string, valuePath *field.Path) field.ErrorList {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return field.ErrorList{field.Invalid(valuePath, value, "expected two comma separated values")}
+	}
+
+	var bounds [2]float64
+	for i, part := range parts {
+		if err := validateScalarValue(dataType, part); err != nil {
+			return field.ErrorList{field.Invalid(valuePath, value, err.Error())}
+		}
+		bounds[i], _ = strconv.ParseFloat(strings.TrimSpace(part), 64)
+	}
+	if bounds[0] > bounds[1] {
+		return field.ErrorList{field.Invalid(valuePath, value, "start of the range is greater than the end")}
+	}
+	return nil
+}
+
+func validateScalarValue(dataType DataType, value string) error {
+	value = strings.TrimSpace(value)
+	switch dataType {
+	case DataTypeInteger:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+	case DataTypeFloat:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%q is not a float", value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,331 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+)
+
+// statusRule is a valid rule group in the flow style of yaml, to be used as the rule of a workload.
+const statusRule = `{type: rule_group, condition: AND, rules: [{type: rule, id: http.status_code, datatype: integer, operator: greater_than_equal, value: "400"}]}`
+
+func TestValidateRateLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		rateLimits []RateLimit
+		// errs are the type and field of every expected error
+		errs []string
+	}{
+		{name: "single rate limit",
+			rateLimits: []RateLimit{{BucketMaxSize: 10, BucketRefillSize: 5, TickDuration: "1m"}}},
+		{name: "rate limits on different ticks",
+			rateLimits: []RateLimit{
+				{BucketMaxSize: 10, BucketRefillSize: 10, TickDuration: "1m"},
+				{BucketMaxSize: 100, BucketRefillSize: 100, TickDuration: "1h"},
+				{BucketMaxSize: 1000, BucketRefillSize: 1000, TickDuration: "24h"},
+			}},
+		{name: "rate limits on ticks one second apart",
+			rateLimits: []RateLimit{
+				{BucketMaxSize: 10, BucketRefillSize: 10, TickDuration: "1m"},
+				{BucketMaxSize: 10, BucketRefillSize: 10, TickDuration: "61s"},
+			}},
+		{name: "same tick spelled the same",
+			rateLimits: []RateLimit{
+				{BucketMaxSize: 10, BucketRefillSize: 10, TickDuration: "1m"},
+				{BucketMaxSize: 20, BucketRefillSize: 20, TickDuration: "1m"},
+			},
+			errs: []string{"FieldValueDuplicate spec.rate_limit[1].tick_duration"}},
+		{name: "60s and 1m are the same tick",
+			rateLimits: []RateLimit{
+				{BucketMaxSize: 10, BucketRefillSize: 10, TickDuration: "60s"},
+				{BucketMaxSize: 20, BucketRefillSize: 20, TickDuration: "1m"},
+			},
+			errs: []string{"FieldValueDuplicate spec.rate_limit[1].tick_duration"}},
+		{name: "90m and 1h30m are the same tick",
+			rateLimits: []RateLimit{
+				{BucketMaxSize: 10, BucketRefillSize: 10, TickDuration: "1h30m"},
+				{BucketMaxSize: 10, BucketRefillSize: 10, TickDuration: "1h"},
+				{BucketMaxSize: 10, BucketRefillSize: 10, TickDuration: "90m"},
+			},
+			errs: []string{"FieldValueDuplicate spec.rate_limit[2].tick_duration"}},
+		{name: "invalid sizes",
+			rateLimits: []RateLimit{
+				{BucketMaxSize: 0, BucketRefillSize: 0, TickDuration: "1m"},
+				{BucketMaxSize: 5, BucketRefillSize: 10, TickDuration: "1h"},
+			},
+			errs: []string{
+				"FieldValueInvalid spec.rate_limit[0].bucket_max_size",
+				"FieldValueInvalid spec.rate_limit[0].bucket_refill_size",
+				"FieldValueInvalid spec.rate_limit[1].bucket_refill_size",
+			}},
+		{name: "invalid tick durations",
+			rateLimits: []RateLimit{
+				{BucketMaxSize: 5, BucketRefillSize: 5, TickDuration: "1 minute"},
+				{BucketMaxSize: 5, BucketRefillSize: 5, TickDuration: "0s"},
+				{BucketMaxSize: 5, BucketRefillSize: 5, TickDuration: "-1m"},
+			},
+			errs: []string{
+				"FieldValueInvalid spec.rate_limit[0].tick_duration",
+				"FieldValueInvalid spec.rate_limit[1].tick_duration",
+				"FieldValueInvalid spec.rate_limit[2].tick_duration",
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allErrs := validateRateLimits(tt.rateLimits, field.NewPath("spec", "rate_limit"))
+			assertErrors(t, allErrs, tt.errs)
+		})
+	}
+}
+
+func TestValidateZerokProbeSpec(t *testing.T) {
+	tests := []struct {
+		name string
+		// namespace of the probe, empty for a ClusterZerokProbe
+		namespace string
+		spec      string
+		errs      []string
+	}{
+		{name: "valid probe", namespace: "team-a", spec: `
+title: errors
+enabled: true
+workloads:
+  OTEL/orders:
+    rule: ` + statusRule + `
+filter:
+  type: workload
+  condition: AND
+  workload_keys: [orders]
+group_by:
+  - workload_key: orders
+    title: status
+    hash: status
+rate_limit:
+  - bucket_max_size: 5
+    bucket_refill_size: 5
+    tick_duration: 1m
+`},
+		{name: "unknown executor", spec: `
+workloads:
+  JAVA/orders:
+    rule: ` + statusRule,
+			errs: []string{"FieldValueInvalid spec.workloads[JAVA/orders]"}},
+		{name: "workload key without a service", spec: `
+workloads:
+  OTEL/:
+    rule: ` + statusRule,
+			errs: []string{"FieldValueInvalid spec.workloads[OTEL/]"}},
+		{name: "workload name used by two executors", spec: `
+workloads:
+  EBPF/orders:
+    namespace: team-a
+    deployment: orders
+    rule: ` + statusRule + `
+  OTEL/orders:
+    rule: ` + statusRule,
+			errs: []string{"FieldValueDuplicate spec.workloads[OTEL/orders]"}},
+		{name: "EBPF workload without namespace and deployment", spec: `
+workloads:
+  EBPF/orders:
+    rule: ` + statusRule,
+			errs: []string{
+				"FieldValueRequired spec.workloads[EBPF/orders].namespace",
+				"FieldValueRequired spec.workloads[EBPF/orders].deployment",
+			}},
+		{name: "EBPF workload with invalid namespace and deployment", spec: `
+workloads:
+  EBPF/orders:
+    namespace: Team_A
+    deployment: orders/v2
+    rule: ` + statusRule,
+			errs: []string{
+				"FieldValueInvalid spec.workloads[EBPF/orders].namespace",
+				"FieldValueInvalid spec.workloads[EBPF/orders].deployment",
+			}},
+		{name: "OTEL workload with namespace and deployment", spec: `
+workloads:
+  OTEL/orders:
+    namespace: team-a
+    deployment: orders
+    rule: ` + statusRule,
+			errs: []string{
+				"FieldValueForbidden spec.workloads[OTEL/orders].namespace",
+				"FieldValueForbidden spec.workloads[OTEL/orders].deployment",
+			}},
+		{name: "unsupported trace role and protocol", spec: `
+workloads:
+  OTEL/orders:
+    trace_role: proxy
+    protocol: SMTP
+    rule: ` + statusRule,
+			errs: []string{
+				"FieldValueNotSupported spec.workloads[OTEL/orders].trace_role",
+				"FieldValueNotSupported spec.workloads[OTEL/orders].protocol",
+			}},
+		{name: "rule which is not a group", spec: `
+workloads:
+  OTEL/orders:
+    rule: {type: rule, id: http.status_code, datatype: integer, operator: equal, value: "404"}
+`,
+			errs: []string{"FieldValueNotSupported spec.workloads[OTEL/orders].rule.type"}},
+		{name: "invalid rules", spec: `
+workloads:
+  OTEL/orders:
+    rule:
+      type: rule_group
+      condition: XOR
+      rules:
+        - {type: rule, datatype: integer, operator: equal, value: "404"}
+        - {type: rule, id: error, datatype: bool, operator: equal, value: "true"}
+        - {type: rule, id: url, datatype: string, operator: matches, value: "(orders"}
+        - {type: rule, id: status, datatype: integer, operator: between, value: "500,400"}
+        - {type: rule, id: status, datatype: integer, operator: equal, value: "404.0"}
+        - {type: rule, id: status, datatype: integer, operator: in, value: "404,five"}
+        - {type: rule, id: latency, datatype: float, operator: greater_than}
+        - {type: rule, id: latency, datatype: duration, operator: greater_than, value: "1s"}
+        - {type: rule_group, condition: AND, rules: []}
+`,
+			errs: []string{
+				"FieldValueNotSupported spec.workloads[OTEL/orders].rule.condition",
+				"FieldValueRequired spec.workloads[OTEL/orders].rule.rules[0].id",
+				"FieldValueNotSupported spec.workloads[OTEL/orders].rule.rules[1].operator",
+				"FieldValueInvalid spec.workloads[OTEL/orders].rule.rules[2].value",
+				"FieldValueInvalid spec.workloads[OTEL/orders].rule.rules[3].value",
+				"FieldValueInvalid spec.workloads[OTEL/orders].rule.rules[4].value",
+				"FieldValueInvalid spec.workloads[OTEL/orders].rule.rules[5].value",
+				"FieldValueRequired spec.workloads[OTEL/orders].rule.rules[6].value",
+				"FieldValueNotSupported spec.workloads[OTEL/orders].rule.rules[7].datatype",
+				"FieldValueRequired spec.workloads[OTEL/orders].rule.rules[8].rules",
+			}},
+		{name: "filter and group_by on unknown workloads", spec: `
+workloads:
+  OTEL/orders:
+    rule: ` + statusRule + `
+filter:
+  type: trace
+  condition: AND
+  workload_keys: [orders, cart]
+  filters:
+    - type: workload
+      condition: NOT
+      workload_keys: [payments]
+group_by:
+  - workload_key: cart
+    title: status
+    hash: status
+`,
+			errs: []string{
+				"FieldValueNotSupported spec.filter.type",
+				"FieldValueNotFound spec.filter.workload_keys[1]",
+				"FieldValueNotSupported spec.filter.filters[0].condition",
+				"FieldValueNotFound spec.filter.filters[0].workload_keys[0]",
+				"FieldValueNotFound spec.group_by[0].workload_key",
+			}},
+		{name: "namespace scope of a cluster probe", spec: `
+workload_scope: Namespace
+workloads:
+  OTEL/orders:
+    rule: ` + statusRule,
+			errs: []string{"FieldValueForbidden spec.workload_scope"}},
+		{name: "EBPF workload of another namespace in a namespace scoped probe", namespace: "team-a", spec: `
+workload_scope: Namespace
+workloads:
+  EBPF/orders:
+    namespace: team-b
+    deployment: orders
+    rule: ` + statusRule,
+			errs: []string{"FieldValueInvalid spec.workloads[EBPF/orders].namespace"}},
+		{name: "unsupported workload scope", spec: `
+workload_scope: Pod
+`,
+			errs: []string{"FieldValueNotSupported spec.workload_scope"}},
+		{name: "selectors", namespace: "team-a", spec: `
+workload_scope: Namespace
+workloads:
+  OTEL/cart:
+    selector:
+      namespace: team-a
+      deployment: cart
+    rule: ` + statusRule + `
+  OTEL/orders:
+    selector:
+      namespace: team-b
+      pod_selector: {}
+    rule: ` + statusRule + `
+  OTEL/payments:
+    selector:
+      namespace: team-a
+      deployment: payments
+      pod_selector: {matchLabels: {app: payments}}
+    rule: ` + statusRule + `
+  EBPF/shipping:
+    namespace: team-a
+    deployment: shipping
+    selector:
+      namespace: team-a
+      deployment: shipping
+    rule: ` + statusRule,
+			errs: []string{
+				"FieldValueForbidden spec.workloads[EBPF/shipping].selector",
+				"FieldValueInvalid spec.workloads[OTEL/orders].selector.namespace",
+				"FieldValueRequired spec.workloads[OTEL/orders].selector.pod_selector",
+				"FieldValueForbidden spec.workloads[OTEL/payments].selector.deployment",
+			}},
+		{name: "template with rendered fields", namespace: "team-a", spec: `
+template:
+  name: ""
+workload_scope: Cluster
+workloads:
+  OTEL/orders:
+    rule: ` + statusRule + `
+rate_limit:
+  - bucket_max_size: 5
+    bucket_refill_size: 5
+    tick_duration: 1m
+`,
+			errs: []string{
+				"FieldValueRequired spec.template.name",
+				"FieldValueForbidden spec.workload_scope",
+				"FieldValueForbidden spec.workloads",
+				"FieldValueForbidden spec.rate_limit",
+			}},
+		{name: "active window", spec: `
+active_from: "2026-01-02T00:00:00Z"
+active_until: "2026-01-01T00:00:00Z"
+ttl: 0s
+schedule:
+  cron: "0 25 * * *"
+  duration: 0s
+`,
+			errs: []string{
+				"FieldValueInvalid spec.active_until",
+				"FieldValueInvalid spec.ttl",
+				"FieldValueInvalid spec.schedule.cron",
+				"FieldValueInvalid spec.schedule.duration",
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &ZerokProbeSpec{}
+			if err := yaml.UnmarshalStrict([]byte(tt.spec), spec); err != nil {
+				t.Fatalf("invalid spec: %v", err)
+			}
+			allErrs := ValidateZerokProbeSpec(spec, tt.namespace, field.NewPath("spec"))
+			assertErrors(t, allErrs, tt.errs)
+		})
+	}
+}
+
+// assertErrors checks that errs holds an error of the type and field of every entry of expected, in order.
+func assertErrors(t *testing.T, errs field.ErrorList, expected []string) {
+	t.Helper()
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %d: %v", len(expected), len(errs), errs)
+	}
+	for i, err := range errs {
+		if got := string(err.Type) + " " + err.Field; got != expected[i] {
+			t.Errorf("error %d: expected %s, got %s (%v)", i, expected[i], got, err)
+		}
+	}
+}
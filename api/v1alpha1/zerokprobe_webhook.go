@@ -0,0 +1,35 @@
+package v1alpha1
+
+import (
+	zkLogger "github.com/zerok-ai/zk-utils-go/logs"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+var zerokProbeWebhookLogTag = "ZerokProbeWebhook"
+
+// SetupWebhookWithManager registers the admission webhooks of ZerokProbe with the manager.
+// The validating webhook only admits probes in the allowedNamespaces, every namespace is allowed when it is empty.
+func (r *ZerokProbe) SetupWebhookWithManager(mgr ctrl.Manager, allowedNamespaces []string) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&ProbeValidator{AllowedNamespaces: allowedNamespaces}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-operator-zerok-ai-v1alpha1-zerokprobe,mutating=true,failurePolicy=fail,sideEffects=None,groups=operator.zerok.ai,resources=zerokprobes,verbs=create;update,versions=v1alpha1,name=mzerokprobe.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &ZerokProbe{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *ZerokProbe) Default() {
+	zkLogger.Debug(zerokProbeWebhookLogTag, "Setting defaults of probe ", r.Name)
+
+	// the spec of a probe being deleted is left as it is, so that removing the finalizer is not blocked
+	if !r.GetDeletionTimestamp().IsZero() {
+		return
+	}
+	SetZerokProbeSpecDefaults(&r.Spec, r.Namespace)
+}
+
+//+kubebuilder:webhook:path=/validate-operator-zerok-ai-v1alpha1-zerokprobe,mutating=false,failurePolicy=fail,sideEffects=None,groups=operator.zerok.ai,resources=zerokprobes,verbs=create;update,versions=v1alpha1,name=vzerokprobe.kb.io,admissionReviewVersions=v1
@@ -0,0 +1,145 @@
+package v1alpha1
+
+import (
+	"time"
+
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Reasons of the Active condition of a probe.
+const (
+	ReasonWithinWindow    = "WithinWindow"
+	ReasonWindowNotOpen   = "WindowNotOpen"
+	ReasonWindowClosed    = "WindowClosed"
+	ReasonOutsideSchedule = "OutsideSchedule"
+	// ReasonNoWorkloadsSelected is set by the operator when the workload selectors of a probe select no pods.
+	ReasonNoWorkloadsSelected = "NoWorkloadsSelected"
+)
+
+// maxScheduleOccurrences bounds the walk over overlapping occurrences of a schedule, e.g. a probe firing every
+// minute for an hour. The probe is requeued at the bound and the walk goes on from there.
+const maxScheduleOccurrences = 1000
+
+// IsWithinActiveWindow tells whether the scenario of a probe is to be stored at now. The window includes active_from
+// and excludes active_until, and is further limited to the occurrences of the schedule. A probe without a window
+// is always active.
+func IsWithinActiveWindow(spec *ZerokProbeSpec, now time.Time) bool {
+	return ActiveWindowReason(spec, now) == ReasonWithinWindow
+}
+
+// ActiveWindowReason returns the reason of the Active condition of a probe at now.
+func ActiveWindowReason(spec *ZerokProbeSpec, now time.Time) string {
+	if spec.ActiveFrom != nil && now.Before(spec.ActiveFrom.Time) {
+		return ReasonWindowNotOpen
+	}
+	if spec.ActiveUntil != nil && !now.Before(spec.ActiveUntil.Time) {
+		return ReasonWindowClosed
+	}
+	if spec.Schedule != nil {
+		if _, active := scheduleOccurrence(spec.Schedule, now); !active {
+			return ReasonOutsideSchedule
+		}
+	}
+	return ReasonWithinWindow
+}
+
+// NextActiveWindowChange returns the next time after now at which the probe enters or leaves its active window or
+// an occurrence of its schedule, nil when the probe does not change anymore.
+func NextActiveWindowChange(spec *ZerokProbeSpec, now time.Time) *time.Time {
+	var next *time.Time
+	for _, bound := range []*metav1.Time{spec.ActiveFrom, spec.ActiveUntil} {
+		if bound != nil && bound.After(now) {
+			next = earliest(next, bound.Time)
+		}
+	}
+	if spec.Schedule != nil && (spec.ActiveUntil == nil || now.Before(spec.ActiveUntil.Time)) {
+		if change, _ := scheduleOccurrence(spec.Schedule, now); !change.IsZero() {
+			next = earliest(next, change)
+		}
+	}
+	return next
+}
+
+// NextScheduledActivation returns the next activation and deactivation of the probe by its schedule, nil for the
+// times that do not come before active_until.
+func NextScheduledActivation(spec *ZerokProbeSpec, now time.Time) (*metav1.Time, *metav1.Time) {
+	if spec.Schedule == nil {
+		return nil, nil
+	}
+	schedule, err := ParseSchedule(spec.Schedule.Cron)
+	if err != nil {
+		return nil, nil
+	}
+
+	change, active := scheduleOccurrence(spec.Schedule, now)
+	var activation, deactivation time.Time
+	if active {
+		deactivation = change
+		activation = schedule.Next(deactivation)
+	} else {
+		activation = change
+		deactivation, _ = scheduleOccurrence(spec.Schedule, activation)
+	}
+	return beforeActiveUntil(spec, activation), beforeActiveUntil(spec, deactivation)
+}
+
+// ParseSchedule parses the cron expression of a schedule.
+func ParseSchedule(expression string) (cron.Schedule, error) {
+	return cron.ParseStandard(expression)
+}
+
+// scheduleOccurrence tells whether an occurrence of the schedule is running at now and returns the time it ends,
+// occurrences which overlap or follow each other right away are joined. When no occurrence is running, the start
+// of the next one is returned instead. An invalid schedule is never active and returns the zero time.
+func scheduleOccurrence(scheduleSpec *ZerokProbeSchedule, now time.Time) (time.Time, bool) {
+	schedule, err := ParseSchedule(scheduleSpec.Cron)
+	if err != nil || scheduleSpec.Duration.Duration <= 0 {
+		return time.Time{}, false
+	}
+	duration := scheduleSpec.Duration.Duration
+
+	// the latest occurrence still running at now is the first one which fires after now - duration
+	start := schedule.Next(now.Add(-duration))
+	if start.IsZero() || start.After(now) {
+		return start, false
+	}
+
+	end := start.Add(duration)
+	for i := 0; i < maxScheduleOccurrences; i++ {
+		next := schedule.Next(start)
+		if next.IsZero() || next.After(end) {
+			break
+		}
+		start = next
+		if next.Add(duration).After(end) {
+			end = next.Add(duration)
+		}
+	}
+	return end, true
+}
+
+func earliest(current *time.Time, candidate time.Time) *time.Time {
+	if current == nil || candidate.Before(*current) {
+		return &candidate
+	}
+	return current
+}
+
+func beforeActiveUntil(spec *ZerokProbeSpec, t time.Time) *metav1.Time {
+	if t.IsZero() || (spec.ActiveUntil != nil && !t.Before(spec.ActiveUntil.Time)) {
+		return nil
+	}
+	mt := metav1.NewTime(t)
+	return &mt
+}
+
+// ProbeExpiresAt returns the time the probe is deleted at, nil for a probe without a ttl.
+func ProbeExpiresAt(probe Probe) *metav1.Time {
+	ttl := probe.GetSpec().TTL
+	if ttl == nil {
+		return nil
+	}
+	expiresAt := metav1.NewTime(probe.GetCreationTimestamp().Add(ttl.Duration))
+	return &expiresAt
+}
@@ -0,0 +1,152 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// at returns the time of day on 2026-03-02 in UTC, a Monday. Day moves it by whole days.
+func at(day int, hour int, minute int) time.Time {
+	return time.Date(2026, time.March, 2+day, hour, minute, 0, 0, time.UTC)
+}
+
+func metaTime(t time.Time) *metav1.Time {
+	mt := metav1.NewTime(t)
+	return &mt
+}
+
+// workdays is active from 09:00 to 17:00 UTC on every weekday.
+var workdays = &ZerokProbeSchedule{Cron: "0 9 * * 1-5", Duration: metav1.Duration{Duration: 8 * time.Hour}}
+
+func TestActiveWindowReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     ZerokProbeSpec
+		now      time.Time
+		expected string
+	}{
+		{name: "no window", now: at(0, 3, 0), expected: ReasonWithinWindow},
+		{name: "before active_from", spec: ZerokProbeSpec{ActiveFrom: metaTime(at(0, 12, 0))},
+			now: at(0, 11, 59), expected: ReasonWindowNotOpen},
+		{name: "at active_from", spec: ZerokProbeSpec{ActiveFrom: metaTime(at(0, 12, 0))},
+			now: at(0, 12, 0), expected: ReasonWithinWindow},
+		{name: "before active_until", spec: ZerokProbeSpec{ActiveUntil: metaTime(at(0, 12, 0))},
+			now: at(0, 11, 59), expected: ReasonWithinWindow},
+		{name: "at active_until", spec: ZerokProbeSpec{ActiveUntil: metaTime(at(0, 12, 0))},
+			now: at(0, 12, 0), expected: ReasonWindowClosed},
+		{name: "start of an occurrence", spec: ZerokProbeSpec{Schedule: workdays},
+			now: at(0, 9, 0), expected: ReasonWithinWindow},
+		{name: "within an occurrence", spec: ZerokProbeSpec{Schedule: workdays},
+			now: at(0, 16, 59), expected: ReasonWithinWindow},
+		{name: "end of an occurrence", spec: ZerokProbeSpec{Schedule: workdays},
+			now: at(0, 17, 0), expected: ReasonOutsideSchedule},
+		{name: "day without an occurrence", spec: ZerokProbeSpec{Schedule: workdays},
+			now: at(5, 10, 0), expected: ReasonOutsideSchedule},
+		{name: "occurrence after active_until", spec: ZerokProbeSpec{Schedule: workdays, ActiveUntil: metaTime(at(0, 12, 0))},
+			now: at(1, 10, 0), expected: ReasonWindowClosed},
+		{name: "schedule in a time zone", spec: ZerokProbeSpec{Schedule: &ZerokProbeSchedule{
+			Cron: "CRON_TZ=Europe/Berlin 0 9 * * *", Duration: metav1.Duration{Duration: time.Hour}}},
+			now: at(0, 8, 30), expected: ReasonWithinWindow},
+		{name: "overlapping occurrences", spec: ZerokProbeSpec{Schedule: &ZerokProbeSchedule{
+			Cron: "*/30 * * * *", Duration: metav1.Duration{Duration: 45 * time.Minute}}},
+			now: at(0, 10, 40), expected: ReasonWithinWindow},
+		{name: "invalid schedule", spec: ZerokProbeSpec{Schedule: &ZerokProbeSchedule{
+			Cron: "0 25 * * *", Duration: metav1.Duration{Duration: time.Hour}}},
+			now: at(0, 10, 0), expected: ReasonOutsideSchedule},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if reason := ActiveWindowReason(&tt.spec, tt.now); reason != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, reason)
+			}
+		})
+	}
+}
+
+func TestNextActiveWindowChange(t *testing.T) {
+	tests := []struct {
+		name string
+		spec ZerokProbeSpec
+		now  time.Time
+		// expected is the zero time when the probe does not change anymore
+		expected time.Time
+	}{
+		{name: "no window", now: at(0, 3, 0)},
+		{name: "before active_from", spec: ZerokProbeSpec{ActiveFrom: metaTime(at(0, 12, 0)), ActiveUntil: metaTime(at(1, 12, 0))},
+			now: at(0, 3, 0), expected: at(0, 12, 0)},
+		{name: "within the window", spec: ZerokProbeSpec{ActiveFrom: metaTime(at(0, 12, 0)), ActiveUntil: metaTime(at(1, 12, 0))},
+			now: at(0, 12, 0), expected: at(1, 12, 0)},
+		{name: "after the window", spec: ZerokProbeSpec{ActiveUntil: metaTime(at(1, 12, 0))},
+			now: at(1, 12, 0)},
+		{name: "within an occurrence", spec: ZerokProbeSpec{Schedule: workdays},
+			now: at(0, 10, 0), expected: at(0, 17, 0)},
+		{name: "between occurrences", spec: ZerokProbeSpec{Schedule: workdays},
+			now: at(0, 17, 0), expected: at(1, 9, 0)},
+		{name: "over the weekend", spec: ZerokProbeSpec{Schedule: workdays},
+			now: at(4, 18, 0), expected: at(7, 9, 0)},
+		{name: "active_until within an occurrence", spec: ZerokProbeSpec{Schedule: workdays, ActiveUntil: metaTime(at(0, 12, 0))},
+			now: at(0, 10, 0), expected: at(0, 12, 0)},
+		{name: "schedule after active_until", spec: ZerokProbeSpec{Schedule: workdays, ActiveUntil: metaTime(at(0, 12, 0))},
+			now: at(0, 13, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := NextActiveWindowChange(&tt.spec, tt.now)
+			if next == nil {
+				next = &time.Time{}
+			}
+			if !next.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, *next)
+			}
+		})
+	}
+}
+
+func TestNextScheduledActivation(t *testing.T) {
+	tests := []struct {
+		name         string
+		spec         ZerokProbeSpec
+		now          time.Time
+		activation   *metav1.Time
+		deactivation *metav1.Time
+	}{
+		{name: "no schedule", now: at(0, 10, 0)},
+		{name: "within an occurrence", spec: ZerokProbeSpec{Schedule: workdays},
+			now: at(0, 10, 0), activation: metaTime(at(1, 9, 0)), deactivation: metaTime(at(0, 17, 0))},
+		{name: "between occurrences", spec: ZerokProbeSpec{Schedule: workdays},
+			now: at(0, 18, 0), activation: metaTime(at(1, 9, 0)), deactivation: metaTime(at(1, 17, 0))},
+		{name: "over the weekend", spec: ZerokProbeSpec{Schedule: workdays},
+			now: at(4, 18, 0), activation: metaTime(at(7, 9, 0)), deactivation: metaTime(at(7, 17, 0))},
+		{name: "times after active_until are left out", spec: ZerokProbeSpec{Schedule: workdays, ActiveUntil: metaTime(at(0, 12, 0))},
+			now: at(0, 10, 0)},
+		{name: "deactivation before active_until", spec: ZerokProbeSpec{Schedule: workdays, ActiveUntil: metaTime(at(1, 8, 0))},
+			now: at(0, 10, 0), deactivation: metaTime(at(0, 17, 0))},
+		{name: "invalid schedule", spec: ZerokProbeSpec{Schedule: &ZerokProbeSchedule{Cron: "every day", Duration: metav1.Duration{Duration: time.Hour}}},
+			now: at(0, 10, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			activation, deactivation := NextScheduledActivation(&tt.spec, tt.now)
+			if !activation.Equal(tt.activation) {
+				t.Errorf("expected activation %v, got %v", tt.activation, activation)
+			}
+			if !deactivation.Equal(tt.deactivation) {
+				t.Errorf("expected deactivation %v, got %v", tt.deactivation, deactivation)
+			}
+		})
+	}
+}
+
+func TestProbeExpiresAt(t *testing.T) {
+	probe := &ZerokProbe{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(at(0, 10, 0))}}
+	if expiresAt := ProbeExpiresAt(probe); expiresAt != nil {
+		t.Errorf("expected a probe without a ttl not to expire, got %v", expiresAt)
+	}
+
+	probe.Spec.TTL = &metav1.Duration{Duration: 36 * time.Hour}
+	if expiresAt := ProbeExpiresAt(probe); !expiresAt.Equal(metaTime(at(1, 22, 0))) {
+		t.Errorf("expected the probe to expire at %v, got %v", at(1, 22, 0), expiresAt)
+	}
+}
@@ -0,0 +1,62 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen=true
+type ZerokProbeTemplateParameter struct {
+	// Name of the parameter, the template refers to it as ${name}.
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z_][a-zA-Z0-9_]*$`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Default is used when a probe does not set the parameter, a parameter without a default must be set.
+	Default *string `json:"default,omitempty"`
+}
+
+// ZerokProbeTemplateSpec is the shape of the probes rendered from the template. The parameters can be used in every
+// string of the workloads, filter, group_by and rate_limit, including the workload keys.
+// +k8s:deepcopy-gen=true
+type ZerokProbeTemplateSpec struct {
+	Parameters []ZerokProbeTemplateParameter `json:"parameters,omitempty"`
+	// +kubebuilder:validation:Enum=Cluster;Namespace
+	WorkloadScope WorkloadScope `json:"workload_scope,omitempty"`
+	Workloads     Workloads     `json:"workloads,omitempty"`
+	Filter        Filter        `json:"filter,omitempty"`
+	GroupBy       []GroupBy     `json:"group_by,omitempty"`
+	RateLimit     []RateLimit   `json:"rate_limit,omitempty"`
+}
+
+// ZerokProbeTemplateRef refers a probe to the ZerokProbeTemplate its spec is rendered from.
+// +k8s:deepcopy-gen=true
+type ZerokProbeTemplateRef struct {
+	// Name of the ZerokProbeTemplate.
+	Name string `json:"name"`
+	// Parameters are the values of the parameters declared by the template.
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Cluster
+// ZerokProbeTemplate declares the shape of probes which only differ in a few values, e.g. the service or a threshold.
+// A ZerokProbe or ClusterZerokProbe refers to it in spec.template along with the values of its parameters.
+type ZerokProbeTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ZerokProbeTemplateSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ZerokProbeTemplateList contains a list of ZerokProbeTemplate
+type ZerokProbeTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ZerokProbeTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ZerokProbeTemplate{}, &ZerokProbeTemplateList{})
+}
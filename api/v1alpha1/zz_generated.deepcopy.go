@@ -10,6 +10,65 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterZerokProbe) DeepCopyInto(out *ClusterZerokProbe) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterZerokProbe.
+func (in *ClusterZerokProbe) DeepCopy() *ClusterZerokProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterZerokProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterZerokProbe) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterZerokProbeList) DeepCopyInto(out *ClusterZerokProbeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterZerokProbe, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterZerokProbeList.
+func (in *ClusterZerokProbeList) DeepCopy() *ClusterZerokProbeList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterZerokProbeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterZerokProbeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExecutorTypeEnum) DeepCopyInto(out *ExecutorTypeEnum) {
 	*out = *in
@@ -176,6 +235,11 @@ func (in Rules) DeepCopy() Rules {
 func (in *Workload) DeepCopyInto(out *Workload) {
 	*out = *in
 	in.Rule.DeepCopyInto(&out.Rule)
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(WorkloadSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Workload.
@@ -207,6 +271,26 @@ func (in WorkloadKeys) DeepCopy() WorkloadKeys {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadSelector) DeepCopyInto(out *WorkloadSelector) {
+	*out = *in
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadSelector.
+func (in *WorkloadSelector) DeepCopy() *WorkloadSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in Workloads) DeepCopyInto(out *Workloads) {
 	{
@@ -287,9 +371,30 @@ func (in *ZerokProbeList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZerokProbeSchedule) DeepCopyInto(out *ZerokProbeSchedule) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZerokProbeSchedule.
+func (in *ZerokProbeSchedule) DeepCopy() *ZerokProbeSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(ZerokProbeSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ZerokProbeSpec) DeepCopyInto(out *ZerokProbeSpec) {
 	*out = *in
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(ZerokProbeTemplateRef)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Workloads != nil {
 		in, out := &in.Workloads, &out.Workloads
 		*out = make(Workloads, len(*in))
@@ -308,6 +413,24 @@ func (in *ZerokProbeSpec) DeepCopyInto(out *ZerokProbeSpec) {
 		*out = make([]RateLimit, len(*in))
 		copy(*out, *in)
 	}
+	if in.ActiveFrom != nil {
+		in, out := &in.ActiveFrom, &out.ActiveFrom
+		*out = (*in).DeepCopy()
+	}
+	if in.ActiveUntil != nil {
+		in, out := &in.ActiveUntil, &out.ActiveUntil
+		*out = (*in).DeepCopy()
+	}
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(ZerokProbeSchedule)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZerokProbeSpec.
@@ -330,6 +453,18 @@ func (in *ZerokProbeStatus) DeepCopyInto(out *ZerokProbeStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+	if in.NextActivation != nil {
+		in, out := &in.NextActivation, &out.NextActivation
+		*out = (*in).DeepCopy()
+	}
+	if in.NextDeactivation != nil {
+		in, out := &in.NextDeactivation, &out.NextDeactivation
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZerokProbeStatus.
@@ -341,3 +476,143 @@ func (in *ZerokProbeStatus) DeepCopy() *ZerokProbeStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZerokProbeTemplate) DeepCopyInto(out *ZerokProbeTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZerokProbeTemplate.
+func (in *ZerokProbeTemplate) DeepCopy() *ZerokProbeTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ZerokProbeTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ZerokProbeTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZerokProbeTemplateList) DeepCopyInto(out *ZerokProbeTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ZerokProbeTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZerokProbeTemplateList.
+func (in *ZerokProbeTemplateList) DeepCopy() *ZerokProbeTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(ZerokProbeTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ZerokProbeTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZerokProbeTemplateParameter) DeepCopyInto(out *ZerokProbeTemplateParameter) {
+	*out = *in
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZerokProbeTemplateParameter.
+func (in *ZerokProbeTemplateParameter) DeepCopy() *ZerokProbeTemplateParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(ZerokProbeTemplateParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZerokProbeTemplateRef) DeepCopyInto(out *ZerokProbeTemplateRef) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZerokProbeTemplateRef.
+func (in *ZerokProbeTemplateRef) DeepCopy() *ZerokProbeTemplateRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ZerokProbeTemplateRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZerokProbeTemplateSpec) DeepCopyInto(out *ZerokProbeTemplateSpec) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]ZerokProbeTemplateParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Workloads != nil {
+		in, out := &in.Workloads, &out.Workloads
+		*out = make(Workloads, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	in.Filter.DeepCopyInto(&out.Filter)
+	if in.GroupBy != nil {
+		in, out := &in.GroupBy, &out.GroupBy
+		*out = make([]GroupBy, len(*in))
+		copy(*out, *in)
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = make([]RateLimit, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZerokProbeTemplateSpec.
+func (in *ZerokProbeTemplateSpec) DeepCopy() *ZerokProbeTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ZerokProbeTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/zerok-ai/zk-operator/internal/evaluator"
+	"github.com/zerok-ai/zk-operator/internal/translator"
+)
+
+const usage = `zkprobe works with ZerokProbe and ClusterZerokProbe manifests without a cluster. Probes using a template
+or workload selectors need a cluster, use the /v1/probes:translate and /v1/probes:evaluate api of the operator for them.
+
+Usage:
+  zkprobe translate -f <probe.yaml>                   print the scenario the probe translates into, - reads from stdin
+  zkprobe evaluate -f <probe.yaml> -s <spans.json>    print which sample spans match the rules of the probe
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "translate":
+		os.Exit(translate(os.Args[2:]))
+	case "evaluate":
+		os.Exit(evaluate(os.Args[2:]))
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// translate prints the translation result as json. The exit code is 1 when the probe has validation errors, so
+// that the command can be used as a check in CI.
+func translate(args []string) int {
+	flags := flag.NewFlagSet("translate", flag.ExitOnError)
+	var file string
+	flags.StringVar(&file, "f", "", "The probe manifest to translate, - reads from stdin.")
+	_ = flags.Parse(args)
+
+	if file == "" {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	manifest, err := readManifest(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error while reading manifest", file, err)
+		return 2
+	}
+
+	result := translator.TranslateManifest(manifest)
+	return printResult(result, len(result.Errors))
+}
+
+// evaluate prints which spans matched which rules and which traces pass the filter. The exit code is 1 when the
+// probe has validation errors or the spans can not be read.
+func evaluate(args []string) int {
+	flags := flag.NewFlagSet("evaluate", flag.ExitOnError)
+	var file, spansFile string
+	flags.StringVar(&file, "f", "", "The probe manifest to evaluate, - reads from stdin.")
+	flags.StringVar(&spansFile, "s", "", "The sample spans in OTLP json or as {\"spans\": [...]}.")
+	_ = flags.Parse(args)
+
+	if file == "" || spansFile == "" {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	manifest, err := readManifest(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error while reading manifest", file, err)
+		return 2
+	}
+	spans, err := os.ReadFile(spansFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error while reading spans", spansFile, err)
+		return 2
+	}
+
+	report := evaluator.EvaluateManifest(manifest, spans, 0)
+	return printResult(report, len(report.Errors))
+}
+
+func printResult(result interface{}, errorCount int) int {
+	output, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error while writing the result", err)
+		return 2
+	}
+	fmt.Println(string(output))
+
+	if errorCount > 0 {
+		return 1
+	}
+	return 0
+}
+
+func readManifest(file string) ([]byte, error) {
+	if file == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(file)
+}
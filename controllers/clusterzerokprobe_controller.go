@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"context"
+	operatorv1alpha1 "github.com/zerok-ai/zk-operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	ctrlhandler "sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// ClusterZerokProbeReconciler reconciles a ClusterZerokProbe object. The probe goes through the same steps as a
+// ZerokProbe, its scenario is written by the same handler into the same scenarios db.
+type ClusterZerokProbeReconciler struct {
+	ZerokProbeReconciler
+}
+
+//+kubebuilder:rbac:groups=operator.zerok.ai,resources=clusterzerokprobes,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=operator.zerok.ai,resources=clusterzerokprobes/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=operator.zerok.ai,resources=clusterzerokprobes/finalizers,verbs=update
+
+func (r *ClusterZerokProbeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return r.reconcileProbe(ctx, req, &operatorv1alpha1.ClusterZerokProbe{})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterZerokProbeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&operatorv1alpha1.ClusterZerokProbe{}).
+		Watches(&source.Kind{Type: &operatorv1alpha1.ZerokProbeTemplate{}},
+			ctrlhandler.EnqueueRequestsFromMapFunc(r.probesOfTemplate(operatorv1alpha1.ClusterZerokProbeKind))).
+		Watches(&source.Kind{Type: &corev1.Pod{}},
+			ctrlhandler.EnqueueRequestsFromMapFunc(r.probesSelecting(operatorv1alpha1.ClusterZerokProbeKind)),
+			builder.WithPredicates(predicate.LabelChangedPredicate{})).
+		Watches(&source.Kind{Type: &appsv1.Deployment{}},
+			ctrlhandler.EnqueueRequestsFromMapFunc(r.probesSelecting(operatorv1alpha1.ClusterZerokProbeKind)),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
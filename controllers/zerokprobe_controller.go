@@ -2,16 +2,30 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	operatorv1alpha1 "github.com/zerok-ai/zk-operator/api/v1alpha1"
 	"github.com/zerok-ai/zk-operator/internal/handler"
+	promMetrics "github.com/zerok-ai/zk-operator/internal/metrics"
+	"github.com/zerok-ai/zk-operator/internal/resolver"
+	"github.com/zerok-ai/zk-operator/internal/translator"
 	zkLogger "github.com/zerok-ai/zk-utils-go/logs"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	ctrlhandler "sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 	"time"
 )
 
@@ -25,21 +39,30 @@ type ZerokProbeReconciler struct {
 
 var (
 	zerokProbeFinalizerName = "operator.zerok.ai/finalizer"
-	probeStatusType         = "ProbeStatus"
 )
 
 const zerokProbeHandlerLogTag = "ZerokProbeHandler"
 
-//+kubebuilder:rbac:groups=operator.zerok.ai.zerok.ai,resources=zerokprobes,verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:groups=operator.zerok.ai.zerok.ai,resources=zerokprobes/status,verbs=get;update;patch
-//+kubebuilder:rbac:groups=operator.zerok.ai.zerok.ai,resources=zerokprobes/finalizers,verbs=update
+// templateListTimeout bounds the listing of the probes affected by a changed template, pod or deployment.
+const templateListTimeout = 10 * time.Second
+
+//+kubebuilder:rbac:groups=operator.zerok.ai,resources=zerokprobes,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=operator.zerok.ai,resources=zerokprobes/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=operator.zerok.ai,resources=zerokprobes/finalizers,verbs=update
+//+kubebuilder:rbac:groups=operator.zerok.ai,resources=zerokprobetemplates,verbs=get;list;watch
 //+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
 
 func (r *ZerokProbeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return r.reconcileProbe(ctx, req, &operatorv1alpha1.ZerokProbe{})
+}
+
+// reconcileProbe reads the probe of the request into zerokProbe and reconciles it, for either kind of probe.
+func (r *ZerokProbeReconciler) reconcileProbe(ctx context.Context, req ctrl.Request, zerokProbe operatorv1alpha1.Probe) (ctrl.Result, error) {
 
-	zkLogger.Info(zerokProbeHandlerLogTag, "Reconciling CRD Probe : ")
+	zkLogger.Info(zerokProbeHandlerLogTag, "Reconciling CRD Probe : ", zerokProbe.GetProbeKind())
 
-	zerokProbe := &operatorv1alpha1.ZerokProbe{}
 	err := r.Get(ctx, req.NamespacedName, zerokProbe)
 
 	if err != nil {
@@ -54,56 +77,151 @@ func (r *ZerokProbeReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	r.Recorder.Event(zerokProbe, "Normal", "ZerokProbeReconciling", fmt.Sprintf("Zerok Probe Reconcile Event."))
 
 	// Reconcile logic for each CRD event
-	_, err = r.reconcileZerokProbeResource(ctx, zerokProbe, req)
+	start := time.Now()
+	result, err := r.reconcileZerokProbeResource(ctx, zerokProbe, req)
+	promMetrics.ReconcileDuration.WithLabelValues(zerokProbe.GetProbeKind(), reconcileResult(err)).Observe(time.Since(start).Seconds())
 	if err != nil {
 		zkLogger.Error(zerokProbeHandlerLogTag, "Failed to reconcile CustomResource ", err)
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	return result, nil
+}
+
+func reconcileResult(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ZerokProbeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&operatorv1alpha1.ZerokProbe{}).
+		Watches(&source.Kind{Type: &operatorv1alpha1.ZerokProbeTemplate{}},
+			ctrlhandler.EnqueueRequestsFromMapFunc(r.probesOfTemplate(operatorv1alpha1.ZerokProbeKind))).
+		Watches(&source.Kind{Type: &corev1.Pod{}},
+			ctrlhandler.EnqueueRequestsFromMapFunc(r.probesSelecting(operatorv1alpha1.ZerokProbeKind)),
+			builder.WithPredicates(predicate.LabelChangedPredicate{})).
+		Watches(&source.Kind{Type: &appsv1.Deployment{}},
+			ctrlhandler.EnqueueRequestsFromMapFunc(r.probesSelecting(operatorv1alpha1.ZerokProbeKind)),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Complete(r)
 }
 
-func (r *ZerokProbeReconciler) reconcileZerokProbeResource(ctx context.Context, zerokProbe *operatorv1alpha1.ZerokProbe, req ctrl.Request) (ctrl.Result, error) {
+// probesOfTemplate returns the requests for the probes of the kind which are rendered from a changed template, so
+// that their scenarios are rendered again. The generation of the probes is unchanged, they go through an update.
+func (r *ZerokProbeReconciler) probesOfTemplate(probeKind string) ctrlhandler.MapFunc {
+	return func(template client.Object) []reconcile.Request {
+		ctx, cancel := context.WithTimeout(context.Background(), templateListTimeout)
+		defer cancel()
+
+		probes, err := operatorv1alpha1.ListProbes(ctx, r)
+		if err != nil {
+			zkLogger.Error(zerokProbeHandlerLogTag, "Error occurred while listing the probes of template ", template.GetName(), " ", err)
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, zerokProbe := range probes {
+			templateRef := zerokProbe.GetSpec().Template
+			if zerokProbe.GetProbeKind() != probeKind || templateRef == nil || templateRef.Name != template.GetName() {
+				continue
+			}
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(zerokProbe)})
+		}
+		return requests
+	}
+}
+
+// probesSelecting returns the requests for the probes of the kind whose workloads select a created, deleted or
+// relabeled pod or a changed deployment, so that the services of their workloads are resolved again.
+func (r *ZerokProbeReconciler) probesSelecting(probeKind string) ctrlhandler.MapFunc {
+	return func(object client.Object) []reconcile.Request {
+		ctx, cancel := context.WithTimeout(context.Background(), templateListTimeout)
+		defer cancel()
+
+		probes, err := operatorv1alpha1.ListProbes(ctx, r)
+		if err != nil {
+			zkLogger.Error(zerokProbeHandlerLogTag, "Error occurred while listing the probes selecting ", object.GetNamespace(), "/", object.GetName(), " ", err)
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, zerokProbe := range probes {
+			if zerokProbe.GetProbeKind() != probeKind {
+				continue
+			}
+			// the workloads of a probe using a template are only known once it is rendered
+			renderedProbe, err := r.ZkCRDProbeHandler.RenderProbe(zerokProbe)
+			if err != nil || !resolver.SelectsObject(renderedProbe.GetSpec(), zerokProbe.GetNamespace(), object) {
+				continue
+			}
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(zerokProbe)})
+		}
+		return requests
+	}
+}
+
+func (r *ZerokProbeReconciler) reconcileZerokProbeResource(ctx context.Context, zerokProbe operatorv1alpha1.Probe, req ctrl.Request) (ctrl.Result, error) {
 
 	// check if it is deletion
 	// examine DeletionTimestamp to determine if object is under deletion
-	if zerokProbe.ObjectMeta.GetDeletionTimestamp().IsZero() {
+	if zerokProbe.GetDeletionTimestamp().IsZero() {
 		// The object is not being deleted
 
-		if zerokProbe.ObjectMeta.UID == "" || zerokProbe.ObjectMeta.Finalizers == nil || len(zerokProbe.ObjectMeta.Finalizers) == 0 {
-			// probe create scenario
-			r.Recorder.Event(zerokProbe, "Normal", "CreatingProbe", fmt.Sprintf("Started Probe Creation Process : %s", zerokProbe.Spec.Title))
-			//so if it does not have our finalizer,
-			// then lets add the finalizer and update the object. This is equivalent
-			// registering our finalizer.
-			creationResult, err := r.handleProbeCreation(ctx, zerokProbe)
-			if err != nil {
-				return creationResult, err
-			}
+		// a probe past its ttl is deleted, its scenario is removed by the finalizer
+		now := time.Now()
+		if expiresAt := operatorv1alpha1.ProbeExpiresAt(zerokProbe); expiresAt != nil && !now.Before(expiresAt.Time) {
+			return ctrl.Result{}, r.deleteExpiredProbe(ctx, zerokProbe)
+		}
 
-			//add only finalizer if creation is successful and no error occurred
-			err = r.addFinalizerIfNotPresent(ctx, zerokProbe)
-			if err != nil {
-				return ctrl.Result{}, err
-			}
-			return ctrl.Result{}, nil
+		// a probe whose status does not record any processed generation is created, everything else is an update.
+		// Resyncs and changes to the metadata or status do not change the generation, the handler skips the store
+		// write for them as the hash of the scenario is unchanged.
+		// The scenario is stored before the status is written, so the status is written once with the outcome
+		// instead of going through intermediate phases.
+		var result ctrl.Result
+		var err error
+		if zerokProbe.GetStatus().ObservedGeneration == 0 {
+			// probe create scenario
+			r.Recorder.Event(zerokProbe, "Normal", "CreatingProbe", fmt.Sprintf("Started Probe Creation Process : %s", zerokProbe.GetSpec().Title))
+			result, err = r.handleProbeCreation(ctx, zerokProbe)
+		} else {
+			// probe is being updated
+			result, err = r.handleProbeUpdate(ctx, zerokProbe)
+		}
+		if err != nil {
+			return result, err
 		}
 
-		// probe is being updated
-		return r.handleProbeUpdate(ctx, zerokProbe)
+		//add only finalizer if creation is successful and no error occurred, this registers our finalizer
+		err = r.addFinalizerIfNotPresent(ctx, zerokProbe)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if result.IsZero() {
+			result.RequeueAfter = requeueAfterNextChange(zerokProbe, now)
+		}
+		return result, nil
 	} else {
 		// The object is being deleted
 		// Let's add here status "Downgrade" to define that this resource begin its process to be terminated.
 		if controllerutil.ContainsFinalizer(zerokProbe, zerokProbeFinalizerName) {
+			deletingCondition := newProbeCondition(operatorv1alpha1.ProbeReady, metav1.ConditionFalse, "ProbeDeleting", "Probe is being deleted.")
+			if err := r.updateProbeStatus(ctx, zerokProbe, operatorv1alpha1.ProbeDeleting, deletingCondition); err != nil {
+				return ctrl.Result{}, err
+			}
+
 			// our finalizer is present, so lets handle any external dependency
 			if err := r.handleProbeDeletion(ctx, zerokProbe); err != nil {
+				// the probe may or may not still be present in redis
+				statusErr := r.updateProbeStatus(ctx, zerokProbe, operatorv1alpha1.ProbeUnknown,
+					newProbeCondition(operatorv1alpha1.ProbeStoredInRedis, metav1.ConditionUnknown, "DeleteFailed", err.Error()))
+				if statusErr != nil {
+					zkLogger.Error(zerokProbeHandlerLogTag, "Error occurred while updating the zerok probe status ", statusErr)
+				}
 				// if fail to delete the external dependency here, return with error
 				// so that it can be retried
 				//reconciliation after 5 seconds to retry
@@ -117,8 +235,9 @@ func (r *ZerokProbeReconciler) reconcileZerokProbeResource(ctx context.Context,
 				return ctrl.Result{RequeueAfter: time.Second * 5}, nil
 			}
 			//TODO:: can be removed
-			err := r.FetchUpdatedProbeObject(ctx, zerokProbe.Namespace, zerokProbe.Name, zerokProbe)
-			if err != nil {
+			// the probe is gone once the last finalizer is removed
+			err := r.FetchUpdatedProbeObject(ctx, zerokProbe.GetNamespace(), zerokProbe.GetName(), zerokProbe)
+			if client.IgnoreNotFound(err) != nil {
 				return ctrl.Result{}, err
 			}
 		}
@@ -127,15 +246,39 @@ func (r *ZerokProbeReconciler) reconcileZerokProbeResource(ctx context.Context,
 	}
 }
 
-func (r *ZerokProbeReconciler) addFinalizerIfNotPresent(ctx context.Context, zerokProbe *operatorv1alpha1.ZerokProbe) error {
+// deleteExpiredProbe deletes a probe whose ttl has passed.
+func (r *ZerokProbeReconciler) deleteExpiredProbe(ctx context.Context, zerokProbe operatorv1alpha1.Probe) error {
+	zkLogger.Info(zerokProbeHandlerLogTag, fmt.Sprintf("Deleting expired Probe: %s", zerokProbe.GetSpec().Title))
+	r.Recorder.Event(zerokProbe, "Normal", "ProbeExpired", fmt.Sprintf("Probe: %s is past its ttl of %s, deleting it", zerokProbe.GetSpec().Title, zerokProbe.GetSpec().TTL.Duration))
+	if err := r.Delete(ctx, zerokProbe); client.IgnoreNotFound(err) != nil {
+		zkLogger.Error(zerokProbeHandlerLogTag, "Error occurred while deleting the expired zerok probe ", err)
+		return err
+	}
+	return nil
+}
+
+// requeueAfterNextChange returns the delay until the probe enters or leaves its active window or expires, whichever
+// comes first. Zero means the probe does not change with time anymore.
+func requeueAfterNextChange(zerokProbe operatorv1alpha1.Probe, now time.Time) time.Duration {
+	next := operatorv1alpha1.NextActiveWindowChange(zerokProbe.GetSpec(), now)
+	if expiresAt := operatorv1alpha1.ProbeExpiresAt(zerokProbe); expiresAt != nil && (next == nil || expiresAt.Time.Before(*next)) {
+		next = &expiresAt.Time
+	}
+	if next == nil {
+		return 0
+	}
+	return next.Sub(now)
+}
+
+func (r *ZerokProbeReconciler) addFinalizerIfNotPresent(ctx context.Context, zerokProbe operatorv1alpha1.Probe) error {
 	if !controllerutil.ContainsFinalizer(zerokProbe, zerokProbeFinalizerName) {
 
-		err := r.FetchUpdatedProbeObject(ctx, zerokProbe.Namespace, zerokProbe.Name, zerokProbe)
+		err := r.FetchUpdatedProbeObject(ctx, zerokProbe.GetNamespace(), zerokProbe.GetName(), zerokProbe)
 		if err != nil {
 			return err
 		}
 
-		zkLogger.Info(zerokProbeHandlerLogTag, fmt.Sprintf("Adding Finalizer to the ZerokProbe: %s", zerokProbe.Spec.Title))
+		zkLogger.Info(zerokProbeHandlerLogTag, fmt.Sprintf("Adding Finalizer to the ZerokProbe: %s", zerokProbe.GetSpec().Title))
 		controllerutil.AddFinalizer(zerokProbe, zerokProbeFinalizerName)
 
 		if err = r.Update(ctx, zerokProbe); err != nil {
@@ -143,7 +286,7 @@ func (r *ZerokProbeReconciler) addFinalizerIfNotPresent(ctx context.Context, zer
 			return err
 		}
 
-		err = r.FetchUpdatedProbeObject(ctx, zerokProbe.Namespace, zerokProbe.Name, zerokProbe)
+		err = r.FetchUpdatedProbeObject(ctx, zerokProbe.GetNamespace(), zerokProbe.GetName(), zerokProbe)
 		if err != nil {
 			return err
 		}
@@ -152,53 +295,199 @@ func (r *ZerokProbeReconciler) addFinalizerIfNotPresent(ctx context.Context, zer
 }
 
 // handleCreation handles the creation of the ZerokProbe
-func (r *ZerokProbeReconciler) handleProbeCreation(ctx context.Context, zerokProbe *operatorv1alpha1.ZerokProbe) (ctrl.Result, error) {
+func (r *ZerokProbeReconciler) handleProbeCreation(ctx context.Context, zerokProbe operatorv1alpha1.Probe) (ctrl.Result, error) {
 
-	_, err := r.ZkCRDProbeHandler.CreateCRDProbe(zerokProbe)
+	scenarioHash, err := r.ZkCRDProbeHandler.CreateCRDProbe(zerokProbe)
+	if handled, statusErr := r.handleProbeWithoutWorkloads(ctx, zerokProbe, err); handled {
+		return ctrl.Result{}, statusErr
+	}
+	if handled, statusErr := r.handleProbeTranslationError(ctx, zerokProbe, err); handled {
+		return ctrl.Result{}, statusErr
+	}
 	if err != nil {
-		zkLogger.Error(zerokProbeHandlerLogTag, fmt.Sprintf("Error While Creating Probe: %s with error: %s", zerokProbe.Spec.Title, err.Error()))
-		r.Recorder.Event(zerokProbe, "Warning", "ErrorWhileCreating", fmt.Sprintf("Error While Creating Probe: %s with error: %s", zerokProbe.Spec.Title, err.Error()))
+		zkLogger.Error(zerokProbeHandlerLogTag, fmt.Sprintf("Error While Creating Probe: %s with error: %s", zerokProbe.GetSpec().Title, err.Error()))
+		r.Recorder.Event(zerokProbe, "Warning", "ErrorWhileCreating", fmt.Sprintf("Error While Creating Probe: %s with error: %s", zerokProbe.GetSpec().Title, err.Error()))
+		if statusErr := r.updateProbeStatusOnStoreFailure(ctx, zerokProbe, err); statusErr != nil {
+			zkLogger.Error(zerokProbeHandlerLogTag, "Error occurred while updating the zerok probe status ", statusErr)
+		}
 		return ctrl.Result{}, err
 	}
 
-	zkLogger.Info(zerokProbeHandlerLogTag, fmt.Sprintf("Successfully Created Probe: %s", zerokProbe.Spec.Title))
-	r.Recorder.Event(zerokProbe, "Normal", "CreatedProbe", fmt.Sprintf("Successfully Created Probe: %s", zerokProbe.Spec.Title))
-	return ctrl.Result{}, nil
+	zkLogger.Info(zerokProbeHandlerLogTag, fmt.Sprintf("Successfully Created Probe: %s", zerokProbe.GetSpec().Title))
+	r.Recorder.Event(zerokProbe, "Normal", "CreatedProbe", fmt.Sprintf("Successfully Created Probe: %s", zerokProbe.GetSpec().Title))
+	return ctrl.Result{}, r.updateProbeStatusOnStoreSuccess(ctx, zerokProbe, scenarioHash)
 }
 
 // handleUpdate handles the update of the ZerokProbe
-func (r *ZerokProbeReconciler) handleProbeUpdate(ctx context.Context, zerokProbe *operatorv1alpha1.ZerokProbe) (ctrl.Result, error) {
-	_, err := r.ZkCRDProbeHandler.UpdateCRDProbe(zerokProbe)
+func (r *ZerokProbeReconciler) handleProbeUpdate(ctx context.Context, zerokProbe operatorv1alpha1.Probe) (ctrl.Result, error) {
+	oldScenarioHash := zerokProbe.GetStatus().ScenarioHash
+	scenarioHash, err := r.ZkCRDProbeHandler.UpdateCRDProbe(zerokProbe)
+	if handled, statusErr := r.handleProbeWithoutWorkloads(ctx, zerokProbe, err); handled {
+		return ctrl.Result{}, statusErr
+	}
+	if handled, statusErr := r.handleProbeTranslationError(ctx, zerokProbe, err); handled {
+		return ctrl.Result{}, statusErr
+	}
 	if err != nil {
-		zkLogger.Error(zerokProbeHandlerLogTag, fmt.Sprintf("Error While Updating Probe: %s with error: %s", zerokProbe.Spec.Title, err.Error()))
-		r.Recorder.Event(zerokProbe, "Warning", "ErrorWhileUpdating", fmt.Sprintf("Error While Updating CRD: %s with error: %s", zerokProbe.Spec.Title, err.Error()))
+		zkLogger.Error(zerokProbeHandlerLogTag, fmt.Sprintf("Error While Updating Probe: %s with error: %s", zerokProbe.GetSpec().Title, err.Error()))
+		r.Recorder.Event(zerokProbe, "Warning", "ErrorWhileUpdating", fmt.Sprintf("Error While Updating CRD: %s with error: %s", zerokProbe.GetSpec().Title, err.Error()))
+		if statusErr := r.updateProbeStatusOnStoreFailure(ctx, zerokProbe, err); statusErr != nil {
+			zkLogger.Error(zerokProbeHandlerLogTag, "Error occurred while updating the zerok probe status ", statusErr)
+		}
 		return ctrl.Result{}, err
 	}
 
-	zkLogger.Info(zerokProbeHandlerLogTag, fmt.Sprintf("Successfully Updated Probe: %s", zerokProbe.Spec.Title))
-	r.Recorder.Event(zerokProbe, "Normal", "UpdatedCRD", fmt.Sprintf("Successfully Updated CRD: %s", zerokProbe.Spec.Title))
-	return ctrl.Result{}, nil
+	// resyncs leave the scenario unchanged, only actual updates are reported
+	if scenarioHash != oldScenarioHash {
+		zkLogger.Info(zerokProbeHandlerLogTag, fmt.Sprintf("Successfully Updated Probe: %s", zerokProbe.GetSpec().Title))
+		r.Recorder.Event(zerokProbe, "Normal", "UpdatedCRD", fmt.Sprintf("Successfully Updated CRD: %s", zerokProbe.GetSpec().Title))
+	}
+	return ctrl.Result{}, r.updateProbeStatusOnStoreSuccess(ctx, zerokProbe, scenarioHash)
+}
+
+// handleProbeTranslationError marks the probe as failed if err says that its spec could not be translated into a
+// scenario. Retrying will not help in that case, so the probe is not requeued until its spec changes.
+func (r *ZerokProbeReconciler) handleProbeTranslationError(ctx context.Context, zerokProbe operatorv1alpha1.Probe, err error) (bool, error) {
+	var translationErr *translator.TranslationError
+	if !errors.As(err, &translationErr) {
+		return false, nil
+	}
+
+	zkLogger.Error(zerokProbeHandlerLogTag, fmt.Sprintf("Invalid Probe: %s with error: %s", zerokProbe.GetSpec().Title, err.Error()))
+	r.Recorder.Event(zerokProbe, "Warning", "InvalidProbe", fmt.Sprintf("Probe: %s could not be translated into a scenario: %s", zerokProbe.GetSpec().Title, err.Error()))
+
+	// conditions about redis are left as they are, they still describe the scenario stored for an older generation
+	zerokProbe.GetStatus().ObservedGeneration = zerokProbe.GetGeneration()
+	return true, r.updateProbeStatus(ctx, zerokProbe, operatorv1alpha1.ProbeFailed,
+		newProbeCondition(operatorv1alpha1.ProbeValidated, metav1.ConditionFalse, "TranslationFailed", err.Error()))
+}
+
+// handleProbeWithoutWorkloads marks the probe as inactive if err says that its workload selectors select no pods.
+// The probe is valid, its scenario is stored again when a selected pod or deployment appears.
+func (r *ZerokProbeReconciler) handleProbeWithoutWorkloads(ctx context.Context, zerokProbe operatorv1alpha1.Probe, err error) (bool, error) {
+	var noWorkloadsErr *translator.NoWorkloadsSelectedError
+	if !errors.As(err, &noWorkloadsErr) {
+		return false, nil
+	}
+
+	// the event is only recorded when the probe becomes inactive, not on every resync
+	active := meta.FindStatusCondition(zerokProbe.GetStatus().Conditions, string(operatorv1alpha1.ProbeActive))
+	if active == nil || active.Reason != operatorv1alpha1.ReasonNoWorkloadsSelected {
+		zkLogger.Info(zerokProbeHandlerLogTag, fmt.Sprintf("Probe: %s selects no workloads: %s", zerokProbe.GetSpec().Title, err.Error()))
+		r.Recorder.Event(zerokProbe, "Normal", operatorv1alpha1.ReasonNoWorkloadsSelected, fmt.Sprintf("Probe: %s is inactive: %s", zerokProbe.GetSpec().Title, err.Error()))
+	}
+
+	zerokProbe.GetStatus().ObservedGeneration = zerokProbe.GetGeneration()
+	zerokProbe.GetStatus().ScenarioHash = ""
+	return true, r.updateProbeStatus(ctx, zerokProbe, operatorv1alpha1.ProbeSucceeded,
+		newProbeCondition(operatorv1alpha1.ProbeValidated, metav1.ConditionTrue, "SpecValid", "Probe spec is valid."),
+		newProbeCondition(operatorv1alpha1.ProbeActive, metav1.ConditionFalse, operatorv1alpha1.ReasonNoWorkloadsSelected, err.Error()),
+		newProbeCondition(operatorv1alpha1.ProbeStoredInRedis, metav1.ConditionFalse, operatorv1alpha1.ReasonNoWorkloadsSelected, "Probe selects no workloads and is not stored in redis."),
+		newProbeCondition(operatorv1alpha1.ProbeReady, metav1.ConditionFalse, operatorv1alpha1.ReasonNoWorkloadsSelected, err.Error()))
 }
 
 // handleDeletion handles the deletion of the ZerokProbe
-func (r *ZerokProbeReconciler) handleProbeDeletion(ctx context.Context, zerokProbe *operatorv1alpha1.ZerokProbe) error {
-	zerokProbeVersion := zerokProbe.GetUID()
-	_, err := r.ZkCRDProbeHandler.DeleteCRDProbe(string(zerokProbeVersion))
+func (r *ZerokProbeReconciler) handleProbeDeletion(ctx context.Context, zerokProbe operatorv1alpha1.Probe) error {
+	_, err := r.ZkCRDProbeHandler.DeleteCRDProbe(translator.ScenarioId(zerokProbe))
 	if err != nil {
-		zkLogger.Error(zerokProbeHandlerLogTag, fmt.Sprintf("Error While Deleting Probe: %s with error: %s", zerokProbe.Spec.Title, err.Error()))
+		zkLogger.Error(zerokProbeHandlerLogTag, fmt.Sprintf("Error While Deleting Probe: %s with error: %s", zerokProbe.GetSpec().Title, err.Error()))
 		return err
 	}
 
-	zkLogger.Info(zerokProbeHandlerLogTag, fmt.Sprintf("Successfully Deleted Probe: %s", zerokProbe.Spec.Title))
+	zkLogger.Info(zerokProbeHandlerLogTag, fmt.Sprintf("Successfully Deleted Probe: %s", zerokProbe.GetSpec().Title))
 	return nil
 }
 
 // Let's re-fetch the Probe Custom Resource after update the status
 // so that we have the latest state of the resource on the cluster
-func (r *ZerokProbeReconciler) FetchUpdatedProbeObject(ctx context.Context, namespace, name string, zerokProbe *operatorv1alpha1.ZerokProbe) error {
+func (r *ZerokProbeReconciler) FetchUpdatedProbeObject(ctx context.Context, namespace, name string, zerokProbe operatorv1alpha1.Probe) error {
 	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, zerokProbe); err != nil {
 		zkLogger.Error(zerokProbeHandlerLogTag, "Error occurred while fetching the zerok probe resource")
 		return err
 	}
 	return nil
 }
+
+// updateProbeStatusOnStoreSuccess marks the current generation of the probe as processed and records the hash of
+// the stored scenario. An enabled probe is ready once its scenario is in redis, a disabled probe or a probe outside
+// of its active window is kept out of redis on purpose.
+func (r *ZerokProbeReconciler) updateProbeStatusOnStoreSuccess(ctx context.Context, zerokProbe operatorv1alpha1.Probe, scenarioHash string) error {
+	zerokProbe.GetStatus().ObservedGeneration = zerokProbe.GetGeneration()
+	zerokProbe.GetStatus().ScenarioHash = scenarioHash
+	validated := newProbeCondition(operatorv1alpha1.ProbeValidated, metav1.ConditionTrue, "SpecValid", "Probe spec translated into a scenario.")
+	active := newActiveCondition(zerokProbe.GetSpec(), time.Now())
+	if !zerokProbe.GetSpec().Enabled {
+		return r.updateProbeStatus(ctx, zerokProbe, operatorv1alpha1.ProbeSucceeded, validated, active,
+			newProbeCondition(operatorv1alpha1.ProbeStoredInRedis, metav1.ConditionFalse, "ProbeDisabled", "Probe is disabled and is not stored in redis."),
+			newProbeCondition(operatorv1alpha1.ProbeReady, metav1.ConditionFalse, "ProbeDisabled", "Probe is disabled."))
+	}
+	if scenarioHash == "" {
+		return r.updateProbeStatus(ctx, zerokProbe, operatorv1alpha1.ProbeSucceeded, validated, active,
+			newProbeCondition(operatorv1alpha1.ProbeStoredInRedis, metav1.ConditionFalse, active.Reason, "Probe is outside of its active window and is not stored in redis."),
+			newProbeCondition(operatorv1alpha1.ProbeReady, metav1.ConditionFalse, active.Reason, active.Message))
+	}
+	return r.updateProbeStatus(ctx, zerokProbe, operatorv1alpha1.ProbeSucceeded, validated, active,
+		newProbeCondition(operatorv1alpha1.ProbeStoredInRedis, metav1.ConditionTrue, "ScenarioStored", "Scenario is stored in redis."),
+		newProbeCondition(operatorv1alpha1.ProbeReady, metav1.ConditionTrue, "ProbeLive", "Probe is live."))
+}
+
+// newActiveCondition tells whether the probe is within its active window at now.
+func newActiveCondition(spec *operatorv1alpha1.ZerokProbeSpec, now time.Time) metav1.Condition {
+	switch reason := operatorv1alpha1.ActiveWindowReason(spec, now); reason {
+	case operatorv1alpha1.ReasonWindowNotOpen:
+		return newProbeCondition(operatorv1alpha1.ProbeActive, metav1.ConditionFalse, reason,
+			fmt.Sprintf("Active window opens at %s.", spec.ActiveFrom.UTC().Format(time.RFC3339)))
+	case operatorv1alpha1.ReasonWindowClosed:
+		return newProbeCondition(operatorv1alpha1.ProbeActive, metav1.ConditionFalse, reason,
+			fmt.Sprintf("Active window closed at %s.", spec.ActiveUntil.UTC().Format(time.RFC3339)))
+	case operatorv1alpha1.ReasonOutsideSchedule:
+		return newProbeCondition(operatorv1alpha1.ProbeActive, metav1.ConditionFalse, reason,
+			fmt.Sprintf("Probe is outside of the occurrences of schedule %q.", spec.Schedule.Cron))
+	default:
+		return newProbeCondition(operatorv1alpha1.ProbeActive, metav1.ConditionTrue, reason, "Probe is within its active window.")
+	}
+}
+
+// updateProbeStatusOnStoreFailure marks the current generation of the probe as failed because redis could not be updated.
+// The content of redis is unknown after a failed write, so the hash is cleared and the next reconcile writes again.
+func (r *ZerokProbeReconciler) updateProbeStatusOnStoreFailure(ctx context.Context, zerokProbe operatorv1alpha1.Probe, storeErr error) error {
+	zerokProbe.GetStatus().ObservedGeneration = zerokProbe.GetGeneration()
+	zerokProbe.GetStatus().ScenarioHash = ""
+	return r.updateProbeStatus(ctx, zerokProbe, operatorv1alpha1.ProbeFailed,
+		newProbeCondition(operatorv1alpha1.ProbeStoredInRedis, metav1.ConditionFalse, "StoreFailed", storeErr.Error()),
+		newProbeCondition(operatorv1alpha1.ProbeReady, metav1.ConditionFalse, "StoreFailed", "Scenario could not be stored in redis."))
+}
+
+// updateProbeStatus sets the phase and conditions on the probe and writes them through the status subresource.
+// Nothing is written when the status is unchanged, so that a resync does not trigger another reconcile.
+func (r *ZerokProbeReconciler) updateProbeStatus(ctx context.Context, zerokProbe operatorv1alpha1.Probe, phase operatorv1alpha1.ZerokProbePhase, conditions ...metav1.Condition) error {
+	oldStatus := zerokProbe.GetStatus().DeepCopy()
+
+	zerokProbe.GetStatus().Phase = phase
+	zerokProbe.GetStatus().ExpiresAt = operatorv1alpha1.ProbeExpiresAt(zerokProbe)
+	zerokProbe.GetStatus().NextActivation, zerokProbe.GetStatus().NextDeactivation =
+		operatorv1alpha1.NextScheduledActivation(zerokProbe.GetSpec(), time.Now())
+	for _, condition := range conditions {
+		condition.ObservedGeneration = zerokProbe.GetGeneration()
+		meta.SetStatusCondition(&zerokProbe.GetStatus().Conditions, condition)
+	}
+
+	if equality.Semantic.DeepEqual(oldStatus, zerokProbe.GetStatus()) {
+		return nil
+	}
+
+	if err := r.Status().Update(ctx, zerokProbe); err != nil {
+		zkLogger.Error(zerokProbeHandlerLogTag, "Error occurred while updating the zerok probe status ", err)
+		return err
+	}
+	return nil
+}
+
+func newProbeCondition(conditionType operatorv1alpha1.ZerokProbeConditionType, status metav1.ConditionStatus, reason, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:    string(conditionType),
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+}
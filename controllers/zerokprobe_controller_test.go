@@ -0,0 +1,222 @@
+package controllers
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	operatorv1alpha1 "github.com/zerok-ai/zk-operator/api/v1alpha1"
+	"github.com/zerok-ai/zk-operator/internal/handler"
+	"github.com/zerok-ai/zk-operator/internal/store"
+	"github.com/zerok-ai/zk-utils-go/scenario/model"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+const probeSpec = `
+title: errors
+enabled: true
+workloads:
+  OTEL/orders:
+    rule: {type: rule_group, condition: AND, rules: [{type: rule, id: http.status_code, datatype: integer, operator: greater_than_equal, value: "400"}]}
+`
+
+// statusCountingClient counts the writes of the status subresource.
+type statusCountingClient struct {
+	client.Client
+	statusUpdates int
+}
+
+func (c *statusCountingClient) Status() client.StatusWriter {
+	return &countingStatusWriter{StatusWriter: c.Client.Status(), client: c}
+}
+
+type countingStatusWriter struct {
+	client.StatusWriter
+	client *statusCountingClient
+}
+
+func (w *countingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	w.client.statusUpdates++
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+// newProbe returns a probe of generation 1 with the spec read from yaml.
+func newProbe(t *testing.T, spec string) *operatorv1alpha1.ZerokProbe {
+	probe := &operatorv1alpha1.ZerokProbe{ObjectMeta: metav1.ObjectMeta{Name: "errors", Namespace: "team-a", UID: "probe-uid", Generation: 1}}
+	if err := yaml.UnmarshalStrict([]byte(spec), &probe.Spec); err != nil {
+		t.Fatalf("invalid spec: %v", err)
+	}
+	return probe
+}
+
+// newReconciler returns a reconciler on a fake client holding objects, which stores the scenarios in memory.
+func newReconciler(t *testing.T, objects ...client.Object) (*ZerokProbeReconciler, *statusCountingClient, *store.MemoryScenarioStore) {
+	scheme := runtime.NewScheme()
+	if err := operatorv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fakeClient := &statusCountingClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()}
+	scenarioStore := store.NewMemoryScenarioStore()
+	reconciler := &ZerokProbeReconciler{
+		Client:            fakeClient,
+		Scheme:            scheme,
+		ZkCRDProbeHandler: &handler.ZkCRDProbeHandler{ScenarioStore: scenarioStore, TemplateReader: fakeClient, WorkloadReader: fakeClient},
+		Recorder:          record.NewFakeRecorder(100),
+	}
+	return reconciler, fakeClient, scenarioStore
+}
+
+// runReconcile runs a reconcile of the probe and returns the probe as stored afterwards.
+func runReconcile(t *testing.T, reconciler *ZerokProbeReconciler) *operatorv1alpha1.ZerokProbe {
+	req := ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "team-a", Name: "errors"}}
+	if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	probe := &operatorv1alpha1.ZerokProbe{}
+	if err := reconciler.Get(context.Background(), req.NamespacedName, probe); client.IgnoreNotFound(err) != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return probe
+}
+
+// events drains the events recorded so far and returns their reasons.
+func events(reconciler *ZerokProbeReconciler) []string {
+	recorder := reconciler.Recorder.(*record.FakeRecorder)
+	var reasons []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			reasons = append(reasons, strings.Fields(event)[1])
+		default:
+			return reasons
+		}
+	}
+}
+
+func TestReconcileCreatesAndUpdatesOnObservedGeneration(t *testing.T) {
+	reconciler, fakeClient, scenarioStore := newReconciler(t, newProbe(t, probeSpec))
+
+	probe := runReconcile(t, reconciler)
+	if fakeClient.statusUpdates != 1 {
+		t.Errorf("expected the status to be written once, got %d writes", fakeClient.statusUpdates)
+	}
+	if probe.Status.Phase != operatorv1alpha1.ProbeSucceeded || probe.Status.ObservedGeneration != 1 {
+		t.Errorf("expected phase Succeeded of generation 1, got %s of %d", probe.Status.Phase, probe.Status.ObservedGeneration)
+	}
+	if !meta.IsStatusConditionTrue(probe.Status.Conditions, string(operatorv1alpha1.ProbeReady)) {
+		t.Errorf("expected the probe to be ready, got %v", probe.Status.Conditions)
+	}
+	if scenario, _ := scenarioStore.Get("probe-uid"); scenario == nil || scenario.Title != "errors" {
+		t.Fatalf("expected the scenario to be stored, got %v", scenario)
+	}
+	if reasons := events(reconciler); !slices.Contains(reasons, "CreatingProbe") || slices.Contains(reasons, "UpdatedCRD") {
+		t.Errorf("expected the probe to be created, got events %v", reasons)
+	}
+
+	// a resync of the same generation leaves the status and the scenario as they are
+	fakeClient.statusUpdates = 0
+	runReconcile(t, reconciler)
+	if fakeClient.statusUpdates != 0 {
+		t.Errorf("expected no status write on a resync, got %d writes", fakeClient.statusUpdates)
+	}
+	if reasons := events(reconciler); slices.Contains(reasons, "CreatingProbe") || slices.Contains(reasons, "UpdatedCRD") {
+		t.Errorf("expected the resync not to store the probe, got events %v", reasons)
+	}
+
+	// a new generation of the spec is an update
+	probe.Spec.Title = "orders errors"
+	probe.Generation = 2
+	if err := reconciler.Update(context.Background(), probe); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fakeClient.statusUpdates = 0
+	probe = runReconcile(t, reconciler)
+	if fakeClient.statusUpdates != 1 {
+		t.Errorf("expected the status to be written once, got %d writes", fakeClient.statusUpdates)
+	}
+	if probe.Status.ObservedGeneration != 2 {
+		t.Errorf("expected generation 2 to be observed, got %d", probe.Status.ObservedGeneration)
+	}
+	if scenario, _ := scenarioStore.Get("probe-uid"); scenario == nil || scenario.Title != "orders errors" {
+		t.Errorf("expected the scenario to be updated, got %v", scenario)
+	}
+	if reasons := events(reconciler); slices.Contains(reasons, "CreatingProbe") || !slices.Contains(reasons, "UpdatedCRD") {
+		t.Errorf("expected the probe to be updated, got events %v", reasons)
+	}
+}
+
+func TestReconcileInvalidProbe(t *testing.T) {
+	probe := newProbe(t, probeSpec)
+	probe.Spec.Workloads["OTEL/orders"] = operatorv1alpha1.Workload{}
+	reconciler, fakeClient, scenarioStore := newReconciler(t, probe)
+
+	probe = runReconcile(t, reconciler)
+	if fakeClient.statusUpdates != 1 {
+		t.Errorf("expected the status to be written once, got %d writes", fakeClient.statusUpdates)
+	}
+	if probe.Status.Phase != operatorv1alpha1.ProbeFailed || probe.Status.ObservedGeneration != 1 {
+		t.Errorf("expected phase Failed of generation 1, got %s of %d", probe.Status.Phase, probe.Status.ObservedGeneration)
+	}
+	if !meta.IsStatusConditionFalse(probe.Status.Conditions, string(operatorv1alpha1.ProbeValidated)) {
+		t.Errorf("expected the probe not to be validated, got %v", probe.Status.Conditions)
+	}
+	if scenario, _ := scenarioStore.Get("probe-uid"); scenario != nil {
+		t.Errorf("expected no scenario to be stored, got %v", scenario)
+	}
+}
+
+func TestReconcileDeletesExpiredProbe(t *testing.T) {
+	probe := newProbe(t, probeSpec)
+	probe.Spec.TTL = &metav1.Duration{Duration: time.Hour}
+	probe.CreationTimestamp = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	probe.Finalizers = []string{zerokProbeFinalizerName}
+	reconciler, _, scenarioStore := newReconciler(t, probe)
+	if err := scenarioStore.Set("probe-uid", model.Scenario{Id: "probe-uid", Title: "errors"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the expired probe is deleted, its finalizer keeps it until the scenario is removed
+	probe = runReconcile(t, reconciler)
+	if probe.DeletionTimestamp.IsZero() {
+		t.Fatalf("expected the expired probe to be deleted")
+	}
+	if reasons := events(reconciler); !slices.Contains(reasons, "ProbeExpired") {
+		t.Errorf("expected the expiry to be recorded, got events %v", reasons)
+	}
+
+	probe = runReconcile(t, reconciler)
+	if probe.Name != "" {
+		t.Errorf("expected the probe to be gone, got %v", probe)
+	}
+	if scenario, _ := scenarioStore.Get("probe-uid"); scenario != nil {
+		t.Errorf("expected the scenario to be deleted, got %v", scenario)
+	}
+}
+
+func TestReconcileRequeuesAtTheExpiryOfTheProbe(t *testing.T) {
+	probe := newProbe(t, probeSpec)
+	probe.Spec.TTL = &metav1.Duration{Duration: time.Hour}
+	probe.CreationTimestamp = metav1.NewTime(time.Now().Add(-30 * time.Minute))
+	reconciler, _, scenarioStore := newReconciler(t, probe)
+
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(probe)}
+	result, err := reconciler.Reconcile(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter <= 0 || result.RequeueAfter > 30*time.Minute {
+		t.Errorf("expected a requeue at the expiry in 30m, got %v", result.RequeueAfter)
+	}
+	if scenario, _ := scenarioStore.Get("probe-uid"); scenario == nil {
+		t.Errorf("expected the scenario of the probe to be stored before it expires")
+	}
+}
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	operatorv1alpha1 "github.com/zerok-ai/zk-operator/api/v1alpha1"
+	"github.com/zerok-ai/zk-operator/internal/handler"
+	zkLogger "github.com/zerok-ai/zk-utils-go/logs"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"time"
+)
+
+const zerokProbeDriftLogTag = "ZerokProbeDriftDetector"
+
+// ZerokProbeDriftDetector periodically compares the ZerokProbes and ClusterZerokProbes in the cluster with the
+// scenarios in redis and repairs the differences, e.g. scenarios left behind by a force-removed finalizer or lost in
+// a redis restore.
+type ZerokProbeDriftDetector struct {
+	client.Client
+	ZkCRDProbeHandler *handler.ZkCRDProbeHandler
+	Interval          time.Duration
+}
+
+var _ manager.Runnable = &ZerokProbeDriftDetector{}
+var _ manager.LeaderElectionRunnable = &ZerokProbeDriftDetector{}
+
+// SetupWithManager adds the drift detector to the Manager, it is started once the caches are synced.
+func (d *ZerokProbeDriftDetector) SetupWithManager(mgr manager.Manager) error {
+	return mgr.Add(d)
+}
+
+// Start runs the drift detection every Interval until the context is cancelled.
+func (d *ZerokProbeDriftDetector) Start(ctx context.Context) error {
+	zkLogger.Info(zerokProbeDriftLogTag, "Starting drift detection with interval ", d.Interval)
+	wait.UntilWithContext(ctx, d.detectDrift, d.Interval)
+	return nil
+}
+
+// NeedLeaderElection makes only the leader repair redis.
+func (d *ZerokProbeDriftDetector) NeedLeaderElection() bool {
+	return true
+}
+
+func (d *ZerokProbeDriftDetector) detectDrift(ctx context.Context) {
+	listProbes := func() ([]operatorv1alpha1.Probe, error) {
+		return operatorv1alpha1.ListProbes(ctx, d)
+	}
+
+	drift, err := d.ZkCRDProbeHandler.ReconcileScenarios(listProbes)
+	if err != nil {
+		zkLogger.Error(zerokProbeDriftLogTag, "Error occurred while repairing the drift between probes and redis ", err)
+	}
+	zkLogger.Info(zerokProbeDriftLogTag, fmt.Sprintf("Drift detection found %d orphaned, %d missing and %d outdated scenarios.",
+		len(drift.Orphaned), len(drift.Missing), len(drift.Outdated)))
+}
@@ -13,9 +13,60 @@ type ClusterContextConfig struct {
 	Port      string `yaml:"port"`
 }
 
+type WebhookConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Port    int    `yaml:"port" env-default:"9443"`
+	CertDir string `yaml:"certDir"`
+}
+
+type DriftDetectionConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Interval between two drift detections, in seconds
+	Interval int `yaml:"interval" env-default:"300"`
+}
+
+type LeaderElectionConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Id is the name of the lease the replicas compete for
+	Id string `yaml:"id" env-default:"96feec81.zerok.ai"`
+	// Namespace of the lease, the namespace of the operator when empty
+	Namespace string `yaml:"namespace"`
+}
+
+type ProbesConfig struct {
+	// AllowedNamespaces are the namespaces probes may be created in, every namespace is allowed when empty
+	AllowedNamespaces []string `yaml:"allowedNamespaces"`
+}
+
+type ShutdownConfig struct {
+	// DrainTimeout is the time the in-flight reconciles get to finish on shutdown, in seconds
+	DrainTimeout int `yaml:"drainTimeout" env-default:"20"`
+	// HttpTimeout is the time the in-flight http requests get to finish on shutdown, in seconds
+	HttpTimeout int `yaml:"httpTimeout" env-default:"5"`
+}
+
+type ConfigMapStoreConfig struct {
+	Name      string `yaml:"name" env-default:"zk-scenarios"`
+	Namespace string `yaml:"namespace" env-default:"zk-client"`
+}
+
+type StoreConfig struct {
+	// Type of the scenario store, one of redis, memory or configmap
+	Type      string               `yaml:"type" env-default:"redis"`
+	ConfigMap ConfigMapStoreConfig `yaml:"configMap"`
+	// PingTimeout is the time the store has to answer a health check, in seconds
+	PingTimeout int `yaml:"pingTimeout" env-default:"2"`
+}
+
 type ZkOperatorConfig struct {
 	Redis          config.RedisConfig    `yaml:"redis"`
 	Http           HttpServerConfig      `yaml:"http"`
 	LogsConfig     logsConfig.LogsConfig `yaml:"logs"`
 	ClusterContext ClusterContextConfig  `yaml:"clusterContext"`
+	Webhook        WebhookConfig         `yaml:"webhook"`
+	DriftDetection DriftDetectionConfig  `yaml:"driftDetection"`
+	Store          StoreConfig           `yaml:"store"`
+	LeaderElection LeaderElectionConfig  `yaml:"leaderElection"`
+	Shutdown       ShutdownConfig        `yaml:"shutdown"`
+	Probes         ProbesConfig          `yaml:"probes"`
 }
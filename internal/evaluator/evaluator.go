@@ -0,0 +1,374 @@
+package evaluator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jmespath/go-jmespath"
+	operatorv1alpha1 "github.com/zerok-ai/zk-operator/api/v1alpha1"
+	"github.com/zerok-ai/zk-utils-go/scenario/model"
+)
+
+// EvaluationResult tells which sample spans matched the workloads of a scenario and which traces pass its filter.
+type EvaluationResult struct {
+	Traces []TraceResult `json:"traces"`
+}
+
+type TraceResult struct {
+	TraceId string `json:"trace_id"`
+	// PassesFilter is true when the trace would be exported for the probe.
+	PassesFilter bool         `json:"passes_filter"`
+	Spans        []SpanResult `json:"spans"`
+}
+
+type SpanResult struct {
+	SpanId    string           `json:"span_id"`
+	Service   string           `json:"service"`
+	Workloads []WorkloadResult `json:"workloads"`
+}
+
+// WorkloadResult is the evaluation of the rule of a workload on a span. Only the workloads which apply to the
+// service and trace role of the span are evaluated.
+type WorkloadResult struct {
+	WorkloadId string       `json:"workload_id"`
+	Workload   string       `json:"workload"`
+	Matched    bool         `json:"matched"`
+	Rules      []RuleResult `json:"rules"`
+}
+
+// RuleResult is the evaluation of a single rule of a workload, Path is its position in the rule tree.
+type RuleResult struct {
+	Path     string `json:"path"`
+	Id       string `json:"id"`
+	Operator string `json:"operator"`
+	Value    string `json:"value,omitempty"`
+	Matched  bool   `json:"matched"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Evaluate evaluates the rules and the filter of the scenario against the sample traces the same way as the
+// collectors do: a trace satisfies a workload if any of its spans matches the rule of the workload, and the
+// filter combines the satisfied workloads with its conditions.
+func Evaluate(scenario model.Scenario, traces []Trace) EvaluationResult {
+	workloadIds := make([]string, 0)
+	if scenario.Workloads != nil {
+		for workloadId := range *scenario.Workloads {
+			workloadIds = append(workloadIds, workloadId)
+		}
+	}
+	sort.Strings(workloadIds)
+
+	result := EvaluationResult{Traces: make([]TraceResult, 0, len(traces))}
+	for _, trace := range traces {
+		traceResult := TraceResult{TraceId: trace.TraceId, Spans: make([]SpanResult, 0, len(trace.Spans))}
+		satisfiedWorkloads := map[string]bool{}
+
+		for _, span := range trace.Spans {
+			spanResult := SpanResult{SpanId: span.SpanId, Service: span.Service, Workloads: make([]WorkloadResult, 0)}
+			for _, workloadId := range workloadIds {
+				workload := (*scenario.Workloads)[workloadId]
+				if !appliesTo(workload, span) {
+					continue
+				}
+				workloadResult := evaluateWorkload(workloadId, workload, span)
+				if workloadResult.Matched {
+					satisfiedWorkloads[workloadId] = true
+				}
+				spanResult.Workloads = append(spanResult.Workloads, workloadResult)
+			}
+			traceResult.Spans = append(traceResult.Spans, spanResult)
+		}
+
+		traceResult.PassesFilter = evaluateFilter(scenario.Filter, satisfiedWorkloads)
+		result.Traces = append(result.Traces, traceResult)
+	}
+	return result
+}
+
+// appliesTo tells if the workload is meant for the span. An EBPF workload is identified by <namespace>/<deployment>,
+// which is taken from the kubernetes resource attributes of the span when they are present, an OTEL workload by the
+// service name of the span.
+func appliesTo(workload model.Workload, span Span) bool {
+	service := span.Service
+	if workload.Executor == model.ExecutorName(operatorv1alpha1.EBPF) {
+		namespace, _ := span.ResourceAttributes["k8s.namespace.name"].(string)
+		deployment, _ := span.ResourceAttributes["k8s.deployment.name"].(string)
+		if namespace != "" && deployment != "" {
+			service = namespace + "/" + deployment
+		}
+	}
+	if service != workload.Service {
+		return false
+	}
+	return span.Kind == "" || workload.TraceRole == "" || span.Kind == string(workload.TraceRole)
+}
+
+func evaluateWorkload(workloadId string, workload model.Workload, span Span) WorkloadResult {
+	workloadResult := WorkloadResult{
+		WorkloadId: workloadId,
+		Workload:   string(workload.Executor) + "/" + workload.Service,
+		Rules:      make([]RuleResult, 0),
+	}
+	workloadResult.Matched = evaluateRule(workload.Rule, "rule", span.valueStore(), &workloadResult.Rules)
+	return workloadResult
+}
+
+// evaluateRule evaluates the rule tree and appends the result of every rule to ruleResults. Unlike the
+// collectors, all the rules of a group are evaluated, so that every rule is reported.
+func evaluateRule(rule model.Rule, path string, valueStore map[string]interface{}, ruleResults *[]RuleResult) bool {
+	if rule.Type != model.RULE_GROUP {
+		ruleResult := evaluateRuleLeaf(rule.RuleLeaf, valueStore)
+		ruleResult.Path = path
+		*ruleResults = append(*ruleResults, ruleResult)
+		return ruleResult.Matched
+	}
+
+	if rule.RuleGroup == nil || rule.RuleGroup.Condition == nil {
+		return false
+	}
+	isAnd := *rule.RuleGroup.Condition == model.AND
+	matched := isAnd
+	for i, childRule := range rule.RuleGroup.Rules {
+		childMatched := evaluateRule(childRule, fmt.Sprintf("%s.rules[%d]", path, i), valueStore, ruleResults)
+		if isAnd {
+			matched = matched && childMatched
+		} else {
+			matched = matched || childMatched
+		}
+	}
+	return matched
+}
+
+func evaluateRuleLeaf(leaf *model.RuleLeaf, valueStore map[string]interface{}) RuleResult {
+	if leaf == nil || leaf.ID == nil || leaf.Operator == nil || leaf.Datatype == nil {
+		return RuleResult{Error: "rule must have an id, a datatype and an operator"}
+	}
+	ruleResult := RuleResult{Id: *leaf.ID, Operator: string(*leaf.Operator)}
+	if leaf.Value != nil {
+		ruleResult.Value = string(*leaf.Value)
+	}
+
+	// functions like #jsonExtract need the attribute stores of the cluster
+	if strings.Contains(*leaf.ID, "#") {
+		ruleResult.Error = "functions in the id are not supported by the local evaluator"
+		return ruleResult
+	}
+	valueFromSpan, err := jmespath.Search(*leaf.ID, valueStore)
+	if err != nil {
+		ruleResult.Error = fmt.Sprintf("invalid id: %v", err)
+		return ruleResult
+	}
+
+	matched, err := evaluateOperator(operatorv1alpha1.DataType(*leaf.Datatype), operatorv1alpha1.OperatorTypes(*leaf.Operator), ruleResult.Value, valueFromSpan)
+	ruleResult.Matched = matched
+	if err != nil {
+		ruleResult.Error = err.Error()
+	}
+	return ruleResult
+}
+
+func evaluateOperator(dataType operatorv1alpha1.DataType, operator operatorv1alpha1.OperatorTypes, valueFromRule string, valueFromSpan interface{}) (bool, error) {
+	switch operator {
+	case operatorv1alpha1.OperatorExists:
+		return valueFromSpan != nil, nil
+	case operatorv1alpha1.OperatorNotExists:
+		return valueFromSpan == nil, nil
+	}
+	if valueFromSpan == nil {
+		return false, fmt.Errorf("value not found in span")
+	}
+
+	switch dataType {
+	case operatorv1alpha1.DataTypeInteger:
+		return evaluateIntegerOperator(operator, valueFromRule, fmt.Sprintf("%v", valueFromSpan))
+	case operatorv1alpha1.DataTypeFloat:
+		return evaluateFloatOperator(operator, valueFromRule, fmt.Sprintf("%v", valueFromSpan))
+	case operatorv1alpha1.DataTypeString:
+		return evaluateStringOperator(operator, valueFromRule, fmt.Sprintf("%v", valueFromSpan))
+	}
+	return false, fmt.Errorf("operator %s is not supported for datatype %s", operator, dataType)
+}
+
+func evaluateStringOperator(operator operatorv1alpha1.OperatorTypes, valueFromRule string, valueFromSpan string) (bool, error) {
+	switch operator {
+	case operatorv1alpha1.OperatorMatches, operatorv1alpha1.OperatorDoesNotMatch:
+		matched, err := regexp.MatchString(valueFromRule, valueFromSpan)
+		if err != nil {
+			return false, err
+		}
+		return matched == (operator == operatorv1alpha1.OperatorMatches), nil
+	case operatorv1alpha1.OperatorEqual:
+		return valueFromSpan == valueFromRule, nil
+	case operatorv1alpha1.OperatorNotEqual:
+		return valueFromSpan != valueFromRule, nil
+	case operatorv1alpha1.OperatorContains:
+		return strings.Contains(valueFromSpan, valueFromRule), nil
+	case operatorv1alpha1.OperatorDoesNotContain:
+		return !strings.Contains(valueFromSpan, valueFromRule), nil
+	case operatorv1alpha1.OperatorIn, operatorv1alpha1.OperatorNotIn:
+		found := false
+		for _, item := range strings.Split(valueFromRule, ",") {
+			if item == valueFromSpan {
+				found = true
+				break
+			}
+		}
+		return found == (operator == operatorv1alpha1.OperatorIn), nil
+	case operatorv1alpha1.OperatorBeginsWith:
+		return strings.HasPrefix(valueFromSpan, valueFromRule), nil
+	case operatorv1alpha1.OperatorDoesNotBeginWith:
+		return !strings.HasPrefix(valueFromSpan, valueFromRule), nil
+	case operatorv1alpha1.OperatorEndsWith:
+		return strings.HasSuffix(valueFromSpan, valueFromRule), nil
+	case operatorv1alpha1.OperatorDoesNotEndWith:
+		return !strings.HasSuffix(valueFromSpan, valueFromRule), nil
+	}
+	return false, fmt.Errorf("string: invalid operator: %s", operator)
+}
+
+// evaluateIntegerOperator compares the values as int64, the same way as the integer evaluator of the collectors: the
+// value of the span must be an integer, the values of between, in and not_in which are not integers are skipped, and
+// a span value which is not an integer is in none of the values of in and not_in.
+func evaluateIntegerOperator(operator operatorv1alpha1.OperatorTypes, valueFromRule string, valueFromSpan string) (bool, error) {
+	number, spanErr := strconv.ParseInt(valueFromSpan, 10, 64)
+	if spanErr != nil {
+		spanErr = fmt.Errorf("value %q in span is not an integer", valueFromSpan)
+	}
+
+	switch operator {
+	case operatorv1alpha1.OperatorBetween, operatorv1alpha1.OperatorNotBetween:
+		if spanErr != nil {
+			return false, spanErr
+		}
+		ruleNumbers := parseIntegers(valueFromRule)
+		if len(ruleNumbers) != 2 {
+			return false, fmt.Errorf("expected two comma separated integers in rule")
+		}
+		inRange := number >= ruleNumbers[0] && number <= ruleNumbers[1]
+		return inRange == (operator == operatorv1alpha1.OperatorBetween), nil
+	case operatorv1alpha1.OperatorIn, operatorv1alpha1.OperatorNotIn:
+		found := false
+		if spanErr == nil {
+			for _, ruleNumber := range parseIntegers(valueFromRule) {
+				if number == ruleNumber {
+					found = true
+					break
+				}
+			}
+		}
+		return found == (operator == operatorv1alpha1.OperatorIn), spanErr
+	}
+
+	if spanErr != nil {
+		return false, spanErr
+	}
+	ruleNumber, err := strconv.ParseInt(valueFromRule, 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("value %q in rule is not an integer", valueFromRule)
+	}
+	switch operator {
+	case operatorv1alpha1.OperatorLessThan:
+		return number < ruleNumber, nil
+	case operatorv1alpha1.OperatorLessThanEqual:
+		return number <= ruleNumber, nil
+	case operatorv1alpha1.OperatorGreaterThan:
+		return number > ruleNumber, nil
+	case operatorv1alpha1.OperatorGreaterThanEqual:
+		return number >= ruleNumber, nil
+	case operatorv1alpha1.OperatorEqual:
+		return number == ruleNumber, nil
+	case operatorv1alpha1.OperatorNotEqual:
+		return number != ruleNumber, nil
+	}
+	return false, fmt.Errorf("integer: invalid operator: %s", operator)
+}
+
+// parseIntegers reads the comma separated integers of a rule value, leaving out the ones which are not integers.
+func parseIntegers(value string) []int64 {
+	numbers := make([]int64, 0)
+	for _, item := range strings.Split(value, ",") {
+		if number, err := strconv.ParseInt(item, 10, 64); err == nil {
+			numbers = append(numbers, number)
+		}
+	}
+	return numbers
+}
+
+func evaluateFloatOperator(operator operatorv1alpha1.OperatorTypes, valueFromRule string, valueFromSpan string) (bool, error) {
+	number, err := strconv.ParseFloat(strings.TrimSpace(valueFromSpan), 64)
+	if err != nil {
+		return false, fmt.Errorf("value %q in span is not a number", valueFromSpan)
+	}
+	ruleNumbers := make([]float64, 0)
+	for _, item := range strings.Split(valueFromRule, ",") {
+		ruleNumber, err := strconv.ParseFloat(strings.TrimSpace(item), 64)
+		if err != nil {
+			return false, fmt.Errorf("value %q in rule is not a number", item)
+		}
+		ruleNumbers = append(ruleNumbers, ruleNumber)
+	}
+
+	switch operator {
+	case operatorv1alpha1.OperatorLessThan:
+		return number < ruleNumbers[0], nil
+	case operatorv1alpha1.OperatorLessThanEqual:
+		return number <= ruleNumbers[0], nil
+	case operatorv1alpha1.OperatorGreaterThan:
+		return number > ruleNumbers[0], nil
+	case operatorv1alpha1.OperatorGreaterThanEqual:
+		return number >= ruleNumbers[0], nil
+	case operatorv1alpha1.OperatorEqual:
+		return number == ruleNumbers[0], nil
+	case operatorv1alpha1.OperatorNotEqual:
+		return number != ruleNumbers[0], nil
+	case operatorv1alpha1.OperatorBetween, operatorv1alpha1.OperatorNotBetween:
+		if len(ruleNumbers) != 2 {
+			return false, fmt.Errorf("expected two comma separated values in rule")
+		}
+		inRange := number >= ruleNumbers[0] && number <= ruleNumbers[1]
+		return inRange == (operator == operatorv1alpha1.OperatorBetween), nil
+	case operatorv1alpha1.OperatorIn, operatorv1alpha1.OperatorNotIn:
+		found := false
+		for _, ruleNumber := range ruleNumbers {
+			if number == ruleNumber {
+				found = true
+				break
+			}
+		}
+		return found == (operator == operatorv1alpha1.OperatorIn), nil
+	}
+	return false, fmt.Errorf("float: invalid operator: %s", operator)
+}
+
+// evaluateFilter combines the satisfied workloads and the nested filters with the condition of the filter.
+func evaluateFilter(filter model.Filter, satisfiedWorkloads map[string]bool) bool {
+	results := make([]bool, 0)
+	if filter.WorkloadIds != nil {
+		for _, workloadId := range *filter.WorkloadIds {
+			results = append(results, satisfiedWorkloads[workloadId])
+		}
+	}
+	if filter.Filters != nil {
+		for _, nestedFilter := range *filter.Filters {
+			results = append(results, evaluateFilter(nestedFilter, satisfiedWorkloads))
+		}
+	}
+
+	if filter.Condition == model.OR {
+		for _, result := range results {
+			if result {
+				return true
+			}
+		}
+		return false
+	}
+	for _, result := range results {
+		if !result {
+			return false
+		}
+	}
+	return true
+}
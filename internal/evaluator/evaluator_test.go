@@ -0,0 +1,179 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"testing"
+
+	operatorv1alpha1 "github.com/zerok-ai/zk-operator/api/v1alpha1"
+	"github.com/zerok-ai/zk-utils-go/scenario/model"
+)
+
+func TestEvaluateOperator(t *testing.T) {
+	tests := []struct {
+		name          string
+		dataType      operatorv1alpha1.DataType
+		operator      operatorv1alpha1.OperatorTypes
+		valueFromRule string
+		valueFromSpan interface{}
+		matched       bool
+		err           bool
+	}{
+		{name: "exists", dataType: operatorv1alpha1.DataTypeString, operator: operatorv1alpha1.OperatorExists, valueFromSpan: "GET", matched: true},
+		{name: "exists without value", dataType: operatorv1alpha1.DataTypeString, operator: operatorv1alpha1.OperatorExists, matched: false},
+		{name: "not_exists without value", dataType: operatorv1alpha1.DataTypeBool, operator: operatorv1alpha1.OperatorNotExists, matched: true},
+		{name: "comparison without value", dataType: operatorv1alpha1.DataTypeString, operator: operatorv1alpha1.OperatorEqual, valueFromRule: "GET", err: true},
+
+		{name: "string equal", dataType: operatorv1alpha1.DataTypeString, operator: operatorv1alpha1.OperatorEqual, valueFromRule: "GET", valueFromSpan: "GET", matched: true},
+		{name: "string not_equal", dataType: operatorv1alpha1.DataTypeString, operator: operatorv1alpha1.OperatorNotEqual, valueFromRule: "GET", valueFromSpan: "get", matched: true},
+		{name: "string matches", dataType: operatorv1alpha1.DataTypeString, operator: operatorv1alpha1.OperatorMatches, valueFromRule: "^/orders/[0-9]+$", valueFromSpan: "/orders/42", matched: true},
+		{name: "string does_not_match", dataType: operatorv1alpha1.DataTypeString, operator: operatorv1alpha1.OperatorDoesNotMatch, valueFromRule: "^/orders/[0-9]+$", valueFromSpan: "/orders/42", matched: false},
+		{name: "string invalid regex", dataType: operatorv1alpha1.DataTypeString, operator: operatorv1alpha1.OperatorMatches, valueFromRule: "(orders", valueFromSpan: "/orders", err: true},
+		{name: "string contains", dataType: operatorv1alpha1.DataTypeString, operator: operatorv1alpha1.OperatorContains, valueFromRule: "orders", valueFromSpan: "/v1/orders/42", matched: true},
+		{name: "string does_not_contain", dataType: operatorv1alpha1.DataTypeString, operator: operatorv1alpha1.OperatorDoesNotContain, valueFromRule: "cart", valueFromSpan: "/v1/orders/42", matched: true},
+		{name: "string in", dataType: operatorv1alpha1.DataTypeString, operator: operatorv1alpha1.OperatorIn, valueFromRule: "GET,POST", valueFromSpan: "POST", matched: true},
+		{name: "string not_in", dataType: operatorv1alpha1.DataTypeString, operator: operatorv1alpha1.OperatorNotIn, valueFromRule: "GET,POST", valueFromSpan: "POST", matched: false},
+		{name: "string begins_with", dataType: operatorv1alpha1.DataTypeString, operator: operatorv1alpha1.OperatorBeginsWith, valueFromRule: "/v1", valueFromSpan: "/v1/orders", matched: true},
+		{name: "string does_not_begin_with", dataType: operatorv1alpha1.DataTypeString, operator: operatorv1alpha1.OperatorDoesNotBeginWith, valueFromRule: "/v2", valueFromSpan: "/v1/orders", matched: true},
+		{name: "string ends_with", dataType: operatorv1alpha1.DataTypeString, operator: operatorv1alpha1.OperatorEndsWith, valueFromRule: "orders", valueFromSpan: "/v1/orders", matched: true},
+		{name: "string does_not_end_with", dataType: operatorv1alpha1.DataTypeString, operator: operatorv1alpha1.OperatorDoesNotEndWith, valueFromRule: "orders", valueFromSpan: "/v1/orders", matched: false},
+		{name: "string of a number", dataType: operatorv1alpha1.DataTypeString, operator: operatorv1alpha1.OperatorEqual, valueFromRule: "404", valueFromSpan: json.Number("404"), matched: true},
+
+		{name: "integer equal", dataType: operatorv1alpha1.DataTypeInteger, operator: operatorv1alpha1.OperatorEqual, valueFromRule: "404", valueFromSpan: json.Number("404"), matched: true},
+		{name: "integer beyond 2^53", dataType: operatorv1alpha1.DataTypeInteger, operator: operatorv1alpha1.OperatorEqual, valueFromRule: "9007199254740993", valueFromSpan: json.Number("9007199254740992"), matched: false},
+		{name: "integer with a fraction in span", dataType: operatorv1alpha1.DataTypeInteger, operator: operatorv1alpha1.OperatorEqual, valueFromRule: "404", valueFromSpan: json.Number("404.0"), err: true},
+		{name: "integer with a fraction in rule", dataType: operatorv1alpha1.DataTypeInteger, operator: operatorv1alpha1.OperatorEqual, valueFromRule: "404.5", valueFromSpan: json.Number("404"), err: true},
+		{name: "integer less_than", dataType: operatorv1alpha1.DataTypeInteger, operator: operatorv1alpha1.OperatorLessThan, valueFromRule: "500", valueFromSpan: json.Number("404"), matched: true},
+		{name: "integer less_than_equal", dataType: operatorv1alpha1.DataTypeInteger, operator: operatorv1alpha1.OperatorLessThanEqual, valueFromRule: "404", valueFromSpan: json.Number("404"), matched: true},
+		{name: "integer greater_than", dataType: operatorv1alpha1.DataTypeInteger, operator: operatorv1alpha1.OperatorGreaterThan, valueFromRule: "404", valueFromSpan: json.Number("404"), matched: false},
+		{name: "integer greater_than_equal", dataType: operatorv1alpha1.DataTypeInteger, operator: operatorv1alpha1.OperatorGreaterThanEqual, valueFromRule: "400", valueFromSpan: json.Number("404"), matched: true},
+		{name: "integer not_equal", dataType: operatorv1alpha1.DataTypeInteger, operator: operatorv1alpha1.OperatorNotEqual, valueFromRule: "404", valueFromSpan: json.Number("500"), matched: true},
+		{name: "integer between", dataType: operatorv1alpha1.DataTypeInteger, operator: operatorv1alpha1.OperatorBetween, valueFromRule: "400,499", valueFromSpan: json.Number("499"), matched: true},
+		{name: "integer not_between", dataType: operatorv1alpha1.DataTypeInteger, operator: operatorv1alpha1.OperatorNotBetween, valueFromRule: "400,499", valueFromSpan: json.Number("500"), matched: true},
+		{name: "integer between skips values which are not integers", dataType: operatorv1alpha1.DataTypeInteger, operator: operatorv1alpha1.OperatorBetween, valueFromRule: "400,x,499", valueFromSpan: json.Number("450"), matched: true},
+		{name: "integer between with one bound", dataType: operatorv1alpha1.DataTypeInteger, operator: operatorv1alpha1.OperatorBetween, valueFromRule: "400", valueFromSpan: json.Number("450"), err: true},
+		{name: "integer in", dataType: operatorv1alpha1.DataTypeInteger, operator: operatorv1alpha1.OperatorIn, valueFromRule: "404,500", valueFromSpan: json.Number("500"), matched: true},
+		{name: "integer in skips values which are not integers", dataType: operatorv1alpha1.DataTypeInteger, operator: operatorv1alpha1.OperatorIn, valueFromRule: "404, 500", valueFromSpan: json.Number("500"), matched: false},
+		{name: "integer not_in with a span value which is not an integer", dataType: operatorv1alpha1.DataTypeInteger, operator: operatorv1alpha1.OperatorNotIn, valueFromRule: "404,500", valueFromSpan: "n/a", matched: true, err: true},
+		{name: "integer invalid operator", dataType: operatorv1alpha1.DataTypeInteger, operator: operatorv1alpha1.OperatorContains, valueFromRule: "404", valueFromSpan: json.Number("404"), err: true},
+
+		{name: "float greater_than", dataType: operatorv1alpha1.DataTypeFloat, operator: operatorv1alpha1.OperatorGreaterThan, valueFromRule: "0.5", valueFromSpan: 0.75, matched: true},
+		{name: "float equal to an integer", dataType: operatorv1alpha1.DataTypeFloat, operator: operatorv1alpha1.OperatorEqual, valueFromRule: "404", valueFromSpan: json.Number("404.0"), matched: true},
+		{name: "float between", dataType: operatorv1alpha1.DataTypeFloat, operator: operatorv1alpha1.OperatorBetween, valueFromRule: "0.1, 0.9", valueFromSpan: 0.9, matched: true},
+		{name: "float not_in", dataType: operatorv1alpha1.DataTypeFloat, operator: operatorv1alpha1.OperatorNotIn, valueFromRule: "0.1,0.2", valueFromSpan: 0.3, matched: true},
+		{name: "float which is not a number", dataType: operatorv1alpha1.DataTypeFloat, operator: operatorv1alpha1.OperatorEqual, valueFromRule: "0.1", valueFromSpan: "fast", err: true},
+
+		{name: "bool comparison", dataType: operatorv1alpha1.DataTypeBool, operator: operatorv1alpha1.OperatorEqual, valueFromRule: "true", valueFromSpan: true, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, err := evaluateOperator(tt.dataType, tt.operator, tt.valueFromRule, tt.valueFromSpan)
+			if matched != tt.matched {
+				t.Errorf("expected matched %v, got %v", tt.matched, matched)
+			}
+			if (err != nil) != tt.err {
+				t.Errorf("expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestEvaluateFilter(t *testing.T) {
+	workloadIds := func(ids ...string) *model.WorkloadIds {
+		workloadIds := model.WorkloadIds(ids)
+		return &workloadIds
+	}
+	filters := func(filters ...model.Filter) *model.Filters {
+		nestedFilters := model.Filters(filters)
+		return &nestedFilters
+	}
+	satisfiedWorkloads := map[string]bool{"orders": true, "cart": true}
+
+	tests := []struct {
+		name     string
+		filter   model.Filter
+		expected bool
+	}{
+		{name: "all workloads satisfied", filter: model.Filter{Condition: model.AND, WorkloadIds: workloadIds("orders", "cart")}, expected: true},
+		{name: "one workload not satisfied", filter: model.Filter{Condition: model.AND, WorkloadIds: workloadIds("orders", "payments")}, expected: false},
+		{name: "any workload satisfied", filter: model.Filter{Condition: model.OR, WorkloadIds: workloadIds("payments", "cart")}, expected: true},
+		{name: "no workload satisfied", filter: model.Filter{Condition: model.OR, WorkloadIds: workloadIds("payments", "shipping")}, expected: false},
+		{name: "nested filters", filter: model.Filter{Condition: model.AND, WorkloadIds: workloadIds("orders"), Filters: filters(
+			model.Filter{Condition: model.OR, WorkloadIds: workloadIds("payments", "cart")},
+		)}, expected: true},
+		{name: "nested filter not satisfied", filter: model.Filter{Condition: model.AND, WorkloadIds: workloadIds("orders"), Filters: filters(
+			model.Filter{Condition: model.AND, WorkloadIds: workloadIds("payments", "cart")},
+		)}, expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if passes := evaluateFilter(tt.filter, satisfiedWorkloads); passes != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, passes)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	id := `attributes."http.status_code"`
+	datatype := model.DataType(operatorv1alpha1.DataTypeInteger)
+	greaterThanEqual := model.OperatorTypes(operatorv1alpha1.OperatorGreaterThanEqual)
+	value := model.ValueTypes("500")
+	and := model.AND
+	rule := model.Rule{Type: model.RULE_GROUP, RuleGroup: &model.RuleGroup{Condition: &and, Rules: model.Rules{
+		{Type: model.RULE, RuleLeaf: &model.RuleLeaf{ID: &id, Datatype: &datatype, Operator: &greaterThanEqual, Value: &value}},
+	}}}
+	workloads := map[string]model.Workload{
+		"orders":   {Service: "orders", TraceRole: "server", Executor: model.ExecutorOTel, Rule: rule},
+		"payments": {Service: "shop/payments", TraceRole: "server", Executor: model.ExecutorName(operatorv1alpha1.EBPF), Rule: rule},
+	}
+	filterIds := model.WorkloadIds{"orders", "payments"}
+	scenario := model.Scenario{Workloads: &workloads, Filter: model.Filter{Condition: model.OR, WorkloadIds: &filterIds}}
+
+	span := func(traceId string, service string, kind string, status int, resourceAttributes map[string]interface{}) Span {
+		return Span{TraceId: traceId, SpanId: traceId + "-" + service, Service: service, Kind: kind,
+			Attributes: map[string]interface{}{"http.status_code": status}, ResourceAttributes: resourceAttributes}
+	}
+	shopPayments := map[string]interface{}{"k8s.namespace.name": "shop", "k8s.deployment.name": "payments"}
+
+	tests := []struct {
+		name         string
+		spans        []Span
+		passesFilter bool
+		// workloads are the workloads evaluated on each span, in order
+		workloads [][]string
+	}{
+		{name: "server span with an error", spans: []Span{span("t1", "orders", "server", 503, nil)},
+			passesFilter: true, workloads: [][]string{{"orders"}}},
+		{name: "server span without an error", spans: []Span{span("t1", "orders", "server", 200, nil)},
+			passesFilter: false, workloads: [][]string{{"orders"}}},
+		{name: "client span of the service", spans: []Span{span("t1", "orders", "client", 503, nil)},
+			passesFilter: false, workloads: [][]string{{}}},
+		{name: "span of another service", spans: []Span{span("t1", "cart", "server", 503, nil)},
+			passesFilter: false, workloads: [][]string{{}}},
+		{name: "EBPF workload matched by its namespace and deployment", spans: []Span{
+			span("t1", "orders", "server", 200, nil),
+			span("t1", "payments-svc", "server", 502, shopPayments),
+		}, passesFilter: true, workloads: [][]string{{"orders"}, {"payments"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Evaluate(scenario, []Trace{{TraceId: "t1", Spans: tt.spans}})
+			if len(result.Traces) != 1 {
+				t.Fatalf("expected 1 trace, got %d", len(result.Traces))
+			}
+			trace := result.Traces[0]
+			if trace.PassesFilter != tt.passesFilter {
+				t.Errorf("expected passes_filter %v, got %v", tt.passesFilter, trace.PassesFilter)
+			}
+			for i, spanResult := range trace.Spans {
+				evaluated := make([]string, 0)
+				for _, workloadResult := range spanResult.Workloads {
+					evaluated = append(evaluated, workloadResult.WorkloadId)
+				}
+				if len(evaluated) != len(tt.workloads[i]) || (len(evaluated) > 0 && evaluated[0] != tt.workloads[i][0]) {
+					t.Errorf("span %d: expected workloads %v, got %v", i, tt.workloads[i], evaluated)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package evaluator
+
+import (
+	"github.com/zerok-ai/zk-operator/internal/translator"
+)
+
+// EvaluationReport is the outcome of evaluating a ZerokProbe or ClusterZerokProbe manifest against sample spans.
+type EvaluationReport struct {
+	Result *EvaluationResult `json:"result,omitempty"`
+	Errors []string          `json:"errors,omitempty"`
+}
+
+// EvaluateManifest translates the probe manifest in yaml or json and evaluates the scenario against the
+// sample spans, see ParseSpans for their format and maxSpans. Like translator.TranslateManifest it works without a
+// cluster, see EvaluateManifestWith for the translation of the operator.
+func EvaluateManifest(manifest []byte, spans []byte, maxSpans int) EvaluationReport {
+	return EvaluateManifestWith(manifest, spans, maxSpans, translator.TranslateZerokProbe)
+}
+
+// EvaluateManifestWith translates the probe manifest with translate and evaluates the scenario against the sample
+// spans.
+func EvaluateManifestWith(manifest []byte, spans []byte, maxSpans int, translate translator.TranslateFunc) EvaluationReport {
+	translation := translator.TranslateManifestWith(manifest, translate)
+	if len(translation.Errors) > 0 {
+		return EvaluationReport{Errors: translation.Errors}
+	}
+
+	traces, err := ParseSpans(spans, maxSpans)
+	if err != nil {
+		return EvaluationReport{Errors: []string{err.Error()}}
+	}
+
+	result := Evaluate(*translation.Scenario, traces)
+	return EvaluationReport{Result: &result}
+}
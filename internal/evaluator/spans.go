@@ -0,0 +1,198 @@
+package evaluator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Span is a sample span the rules of a probe are evaluated against.
+type Span struct {
+	TraceId            string                 `json:"trace_id"`
+	SpanId             string                 `json:"span_id"`
+	ParentSpanId       string                 `json:"parent_span_id,omitempty"`
+	Name               string                 `json:"name,omitempty"`
+	Service            string                 `json:"service,omitempty"`
+	Kind               string                 `json:"kind,omitempty"`
+	Attributes         map[string]interface{} `json:"attributes,omitempty"`
+	ResourceAttributes map[string]interface{} `json:"resource_attributes,omitempty"`
+}
+
+// Trace is the set of sample spans sharing a trace id.
+type Trace struct {
+	TraceId string `json:"trace_id"`
+	Spans   []Span `json:"spans"`
+}
+
+// valueStore is the object the id of a rule is resolved against, e.g. attributes."http.status_code".
+func (s Span) valueStore() map[string]interface{} {
+	return map[string]interface{}{
+		"trace_id":            s.TraceId,
+		"span_id":             s.SpanId,
+		"parent_span_id":      s.ParentSpanId,
+		"name":                s.Name,
+		"service":             s.Service,
+		"kind":                s.Kind,
+		"attributes":          s.Attributes,
+		"resource_attributes": s.ResourceAttributes,
+	}
+}
+
+// ParseSpans reads sample spans either in OTLP json, as exported by the OpenTelemetry collector, or as
+// {"spans": [...]} in the format of Span. The spans are grouped into traces in the order they are first seen. More
+// than maxSpans spans are rejected, 0 reads any number of spans.
+func ParseSpans(data []byte, maxSpans int) ([]Trace, error) {
+	var document struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+		Spans         []Span              `json:"spans"`
+	}
+	// numbers are kept as written, so that integers beyond 2^53 are compared exactly
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("invalid spans: %v", err)
+	}
+
+	spans := document.Spans
+	for _, resourceSpans := range document.ResourceSpans {
+		otlpSpans, err := resourceSpans.toSpans()
+		if err != nil {
+			return nil, err
+		}
+		spans = append(spans, otlpSpans...)
+	}
+	if len(spans) == 0 {
+		return nil, fmt.Errorf("invalid spans: expected resourceSpans or spans")
+	}
+	if maxSpans > 0 && len(spans) > maxSpans {
+		return nil, fmt.Errorf("invalid spans: %d spans, at most %d can be evaluated at once", len(spans), maxSpans)
+	}
+
+	traces := make([]Trace, 0)
+	traceIndex := map[string]int{}
+	for _, span := range spans {
+		i, ok := traceIndex[span.TraceId]
+		if !ok {
+			i = len(traces)
+			traceIndex[span.TraceId] = i
+			traces = append(traces, Trace{TraceId: span.TraceId})
+		}
+		traces[i].Spans = append(traces[i].Spans, span)
+	}
+	return traces, nil
+}
+
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans []struct {
+		Spans []otlpSpan `json:"spans"`
+	} `json:"scopeSpans"`
+}
+
+type otlpSpan struct {
+	TraceId      string          `json:"traceId"`
+	SpanId       string          `json:"spanId"`
+	ParentSpanId string          `json:"parentSpanId"`
+	Name         string          `json:"name"`
+	Kind         json.RawMessage `json:"kind"`
+	Attributes   []otlpKeyValue  `json:"attributes"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string         `json:"stringValue"`
+	BoolValue   *bool           `json:"boolValue"`
+	IntValue    json.RawMessage `json:"intValue"`
+	DoubleValue *float64        `json:"doubleValue"`
+	ArrayValue  *struct {
+		Values []otlpAnyValue `json:"values"`
+	} `json:"arrayValue"`
+	KvlistValue *struct {
+		Values []otlpKeyValue `json:"values"`
+	} `json:"kvlistValue"`
+}
+
+// otlpSpanKinds maps the span kinds of OTLP to the trace roles of a workload.
+var otlpSpanKinds = map[string]string{
+	"1": "internal", "SPAN_KIND_INTERNAL": "internal",
+	"2": "server", "SPAN_KIND_SERVER": "server",
+	"3": "client", "SPAN_KIND_CLIENT": "client",
+	"4": "producer", "SPAN_KIND_PRODUCER": "producer",
+	"5": "consumer", "SPAN_KIND_CONSUMER": "consumer",
+}
+
+func (r otlpResourceSpans) toSpans() ([]Span, error) {
+	resourceAttributes, err := toAttributes(r.Resource.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	service, _ := resourceAttributes["service.name"].(string)
+
+	spans := make([]Span, 0)
+	for _, scopeSpans := range r.ScopeSpans {
+		for _, otlpSpan := range scopeSpans.Spans {
+			attributes, err := toAttributes(otlpSpan.Attributes)
+			if err != nil {
+				return nil, err
+			}
+			spans = append(spans, Span{
+				TraceId:            otlpSpan.TraceId,
+				SpanId:             otlpSpan.SpanId,
+				ParentSpanId:       otlpSpan.ParentSpanId,
+				Name:               otlpSpan.Name,
+				Service:            service,
+				Kind:               otlpSpanKinds[strings.Trim(string(otlpSpan.Kind), `"`)],
+				Attributes:         attributes,
+				ResourceAttributes: resourceAttributes,
+			})
+		}
+	}
+	return spans, nil
+}
+
+func toAttributes(keyValues []otlpKeyValue) (map[string]interface{}, error) {
+	attributes := make(map[string]interface{}, len(keyValues))
+	for _, keyValue := range keyValues {
+		value, err := keyValue.Value.toValue()
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of attribute %s: %v", keyValue.Key, err)
+		}
+		attributes[keyValue.Key] = value
+	}
+	return attributes, nil
+}
+
+func (v otlpAnyValue) toValue() (interface{}, error) {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue, nil
+	case v.BoolValue != nil:
+		return *v.BoolValue, nil
+	case v.IntValue != nil:
+		// int64 values are encoded as strings in OTLP json
+		return strconv.ParseInt(strings.Trim(string(v.IntValue), `"`), 10, 64)
+	case v.DoubleValue != nil:
+		return *v.DoubleValue, nil
+	case v.ArrayValue != nil:
+		values := make([]interface{}, 0, len(v.ArrayValue.Values))
+		for _, item := range v.ArrayValue.Values {
+			value, err := item.toValue()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	case v.KvlistValue != nil:
+		return toAttributes(v.KvlistValue.Values)
+	}
+	return nil, nil
+}
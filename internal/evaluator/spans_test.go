@@ -0,0 +1,76 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const otlpSpans = `{"resourceSpans": [{
+  "resource": {"attributes": [
+    {"key": "service.name", "value": {"stringValue": "orders"}},
+    {"key": "k8s.namespace.name", "value": {"stringValue": "shop"}}
+  ]},
+  "scopeSpans": [{"spans": [
+    {"traceId": "t1", "spanId": "s1", "name": "GET /orders", "kind": 2, "attributes": [
+      {"key": "http.status_code", "value": {"intValue": "9007199254740993"}},
+      {"key": "http.retried", "value": {"boolValue": true}},
+      {"key": "db.latency", "value": {"doubleValue": 0.5}},
+      {"key": "http.hosts", "value": {"arrayValue": {"values": [{"stringValue": "a"}, {"stringValue": "b"}]}}}
+    ]},
+    {"traceId": "t2", "spanId": "s2", "kind": "SPAN_KIND_CLIENT"},
+    {"traceId": "t1", "spanId": "s3", "parentSpanId": "s1", "kind": 3}
+  ]}]
+}]}`
+
+func TestParseSpans(t *testing.T) {
+	resourceAttributes := map[string]interface{}{"service.name": "orders", "k8s.namespace.name": "shop"}
+	tests := []struct {
+		name     string
+		data     string
+		maxSpans int
+		expected []Trace
+		err      bool
+	}{
+		{name: "OTLP json", data: otlpSpans, expected: []Trace{
+			{TraceId: "t1", Spans: []Span{
+				{TraceId: "t1", SpanId: "s1", Name: "GET /orders", Service: "orders", Kind: "server", Attributes: map[string]interface{}{
+					"http.status_code": int64(9007199254740993),
+					"http.retried":     true,
+					"db.latency":       0.5,
+					"http.hosts":       []interface{}{"a", "b"},
+				}, ResourceAttributes: resourceAttributes},
+				{TraceId: "t1", SpanId: "s3", ParentSpanId: "s1", Service: "orders", Kind: "client",
+					Attributes: map[string]interface{}{}, ResourceAttributes: resourceAttributes},
+			}},
+			{TraceId: "t2", Spans: []Span{
+				{TraceId: "t2", SpanId: "s2", Service: "orders", Kind: "client",
+					Attributes: map[string]interface{}{}, ResourceAttributes: resourceAttributes},
+			}},
+		}},
+		{name: "spans", data: `{"spans": [{"trace_id": "t1", "span_id": "s1", "service": "orders", "kind": "server",
+			"attributes": {"http.status_code": 9007199254740993}}]}`, expected: []Trace{
+			{TraceId: "t1", Spans: []Span{
+				{TraceId: "t1", SpanId: "s1", Service: "orders", Kind: "server",
+					Attributes: map[string]interface{}{"http.status_code": json.Number("9007199254740993")}},
+			}},
+		}},
+		{name: "spans within the limit", data: otlpSpans, maxSpans: 3, expected: nil},
+		{name: "spans beyond the limit", data: otlpSpans, maxSpans: 2, err: true},
+		{name: "no spans", data: `{"spans": []}`, err: true},
+		{name: "invalid json", data: `{"spans": [`, err: true},
+		{name: "invalid int value", data: `{"resourceSpans": [{"scopeSpans": [{"spans": [{"traceId": "t1", "attributes": [
+			{"key": "http.status_code", "value": {"intValue": "404.0"}}]}]}]}]}`, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			traces, err := ParseSpans([]byte(tt.data), tt.maxSpans)
+			if (err != nil) != tt.err {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if tt.expected != nil && !reflect.DeepEqual(traces, tt.expected) {
+				t.Errorf("unexpected traces\n got: %+v\nwant: %+v", traces, tt.expected)
+			}
+		})
+	}
+}
@@ -5,14 +5,34 @@ import (
 	"github.com/zerok-ai/zk-operator/internal"
 	"github.com/zerok-ai/zk-operator/internal/utils"
 	zklogger "github.com/zerok-ai/zk-utils-go/logs"
+	"time"
 )
 
 var healthCheckTag = "healthCheckHandler"
 
+const (
+	HealthStatusHealthy   = "healthy"
+	HealthStatusUnhealthy = "unhealthy"
+)
+
 type HealthCheckHandler struct {
 	ZkModules []internal.ZkOperatorModule
 }
 
+// HealthResponse is the body of GET /healthz.
+type HealthResponse struct {
+	Status  string         `json:"status"`
+	Modules []ModuleHealth `json:"modules"`
+}
+
+// ModuleHealth is the result of the health check of a single ZkOperatorModule.
+type ModuleHealth struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	LastError string `json:"last_error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
 func (h *HealthCheckHandler) Init(zkModules []internal.ZkOperatorModule) {
 	h.ZkModules = zkModules
 }
@@ -20,19 +40,31 @@ func (h *HealthCheckHandler) Init(zkModules []internal.ZkOperatorModule) {
 func (h *HealthCheckHandler) Handler(ctx iris.Context) {
 	if len(h.ZkModules) == 0 {
 		ctx.StatusCode(502)
+		return
 	}
-	isHealthy := true
+
+	response := HealthResponse{Status: HealthStatusHealthy, Modules: make([]ModuleHealth, 0, len(h.ZkModules))}
 	for _, module := range h.ZkModules {
-		if !module.IsHealthy() {
-			moduleName := utils.GetTypeName(module)
+		moduleName := utils.GetTypeName(module)
+
+		start := time.Now()
+		isHealthy := module.IsHealthy()
+		moduleHealth := ModuleHealth{Name: moduleName, Status: HealthStatusHealthy, LatencyMs: time.Since(start).Milliseconds()}
+		if !isHealthy {
 			zklogger.Debug(healthCheckTag, "Module ", moduleName, " is not healthy.")
-			isHealthy = false
-			break
+			moduleHealth.Status = HealthStatusUnhealthy
+			response.Status = HealthStatusUnhealthy
+			if err := module.LastError(); err != nil {
+				moduleHealth.LastError = err.Error()
+			}
 		}
+		response.Modules = append(response.Modules, moduleHealth)
 	}
-	if isHealthy {
+
+	if response.Status == HealthStatusHealthy {
 		ctx.StatusCode(200)
 	} else {
 		ctx.StatusCode(500)
 	}
+	_ = ctx.JSON(response)
 }
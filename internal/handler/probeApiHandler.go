@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kataras/iris/v12"
+	operatorv1alpha1 "github.com/zerok-ai/zk-operator/api/v1alpha1"
+	"github.com/zerok-ai/zk-operator/internal/evaluator"
+	"github.com/zerok-ai/zk-operator/internal/translator"
+	zklogger "github.com/zerok-ai/zk-utils-go/logs"
+	"github.com/zerok-ai/zk-utils-go/scenario/model"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+)
+
+var probeApiTag = "probeApiHandler"
+
+// The manifests and spans posted to the api are bounded, so that a single request can not hold the memory or the cpu
+// of the operator.
+const (
+	maxProbeRequestBodySize = 1 << 20
+	maxEvaluatedSpans       = 1000
+)
+
+// ProbeApiHandler serves the read only api to inspect the probes and the scenarios stored for them.
+type ProbeApiHandler struct {
+	// Reader lists the probes, usually the cached client of the manager.
+	Reader            client.Reader
+	ZkCRDProbeHandler *ZkCRDProbeHandler
+}
+
+// ProbeResponse describes a probe along with the scenario stored for it in redis.
+type ProbeResponse struct {
+	Id              string                            `json:"id"`
+	Kind            string                            `json:"kind"`
+	Namespace       string                            `json:"namespace,omitempty"`
+	Name            string                            `json:"name"`
+	Spec            operatorv1alpha1.ZerokProbeSpec   `json:"spec"`
+	Status          operatorv1alpha1.ZerokProbeStatus `json:"status"`
+	ScenarioVersion string                            `json:"scenario_version,omitempty"`
+	SyncStatus      ScenarioSyncStatus                `json:"sync_status"`
+}
+
+type probeApiError struct {
+	Error string `json:"error"`
+}
+
+func (h *ProbeApiHandler) Init(reader client.Reader, zkCRDProbeHandler *ZkCRDProbeHandler) {
+	h.Reader = reader
+	h.ZkCRDProbeHandler = zkCRDProbeHandler
+}
+
+// ListProbes handles GET /v1/probes.
+func (h *ProbeApiHandler) ListProbes(ctx iris.Context) {
+	zerokProbes, err := h.listProbes(ctx.Request().Context())
+	if err != nil {
+		zklogger.Error(probeApiTag, "Error while listing probes ", err)
+		h.writeError(ctx, iris.StatusInternalServerError, err.Error())
+		return
+	}
+
+	probes := make([]ProbeResponse, 0, len(zerokProbes))
+	for _, zerokProbe := range zerokProbes {
+		probes = append(probes, h.toProbeResponse(zerokProbe))
+	}
+	_ = ctx.JSON(probes)
+}
+
+// GetProbe handles GET /v1/probes/{id}.
+func (h *ProbeApiHandler) GetProbe(ctx iris.Context) {
+	zerokProbe, ok := h.findProbe(ctx)
+	if !ok {
+		return
+	}
+	_ = ctx.JSON(h.toProbeResponse(zerokProbe))
+}
+
+// GetProbeScenario handles GET /v1/probes/{id}/scenario and returns the scenario as stored in redis.
+func (h *ProbeApiHandler) GetProbeScenario(ctx iris.Context) {
+	zerokProbe, ok := h.findProbe(ctx)
+	if !ok {
+		return
+	}
+	scenario := h.ZkCRDProbeHandler.GetStoredScenario(translator.ScenarioId(zerokProbe))
+	if scenario == nil {
+		h.writeError(ctx, iris.StatusNotFound, "no scenario is stored for probe "+zerokProbe.GetName())
+		return
+	}
+	_ = ctx.JSON(scenario)
+}
+
+// TranslateProbe handles POST /v1/probes:translate. The body is a probe manifest in yaml or json, the response
+// is the scenario it translates into, or the validation errors with status 422. The probe is translated like the
+// reconciler does, i.e. its template is rendered and its selectors are resolved, but nothing is written to the
+// cluster or the store. See translate for the probes the api accepts.
+func (h *ProbeApiHandler) TranslateProbe(ctx iris.Context) {
+	ctx.SetMaxRequestBodySize(maxProbeRequestBodySize)
+	manifest, err := ctx.GetBody()
+	if err != nil {
+		h.writeBodyError(ctx, err)
+		return
+	}
+
+	result := translator.TranslateManifestWith(manifest, h.translate)
+	if len(result.Errors) > 0 {
+		ctx.StatusCode(iris.StatusUnprocessableEntity)
+	}
+	_ = ctx.JSON(result)
+}
+
+// EvaluateProbeRequest is the body of POST /v1/probes:evaluate. Probe is the ZerokProbe manifest as a json object
+// or as a yaml string, Spans are the sample spans in OTLP json or as {"spans": [...]}.
+type EvaluateProbeRequest struct {
+	Probe json.RawMessage `json:"probe"`
+	Spans json.RawMessage `json:"spans"`
+}
+
+// EvaluateProbe handles POST /v1/probes:evaluate. The response tells which sample spans matched which rules and
+// which traces pass the filter of the probe, or the errors with status 422.
+func (h *ProbeApiHandler) EvaluateProbe(ctx iris.Context) {
+	ctx.SetMaxRequestBodySize(maxProbeRequestBodySize)
+	var request EvaluateProbeRequest
+	if err := ctx.ReadJSON(&request); err != nil {
+		h.writeBodyError(ctx, err)
+		return
+	}
+
+	manifest := []byte(request.Probe)
+	var yamlManifest string
+	if err := json.Unmarshal(request.Probe, &yamlManifest); err == nil {
+		manifest = []byte(yamlManifest)
+	}
+
+	report := evaluator.EvaluateManifestWith(manifest, request.Spans, maxEvaluatedSpans, h.translate)
+	if len(report.Errors) > 0 {
+		ctx.StatusCode(iris.StatusUnprocessableEntity)
+	}
+	_ = ctx.JSON(report)
+}
+
+// translate translates a probe posted to the api like the reconciler does. The api is not authenticated, so when
+// the allowed namespaces restrict the probes, only a ZerokProbe of an allowed namespace is translated, and its
+// selectors may only read the pods of allowed namespaces.
+func (h *ProbeApiHandler) translate(zerokProbe operatorv1alpha1.Probe) (model.Scenario, error) {
+	allowedNamespaces := h.ZkCRDProbeHandler.AllowedNamespaces
+	if len(allowedNamespaces) == 0 {
+		return h.ZkCRDProbeHandler.translate(zerokProbe)
+	}
+
+	if zerokProbe.GetProbeKind() != operatorv1alpha1.ZerokProbeKind {
+		return model.Scenario{}, &translator.TranslationError{Errs: field.ErrorList{
+			field.Forbidden(field.NewPath("kind"), "only ZerokProbes are translated while the probe namespaces are restricted"),
+		}}
+	}
+	if zerokProbe.GetNamespace() == "" {
+		return model.Scenario{}, &translator.TranslationError{Errs: field.ErrorList{
+			field.Required(field.NewPath("metadata", "namespace"), "must be one of the allowed namespaces"),
+		}}
+	}
+	if errs := operatorv1alpha1.ValidateProbeNamespace(zerokProbe, allowedNamespaces); len(errs) > 0 {
+		return model.Scenario{}, &translator.TranslationError{Errs: errs}
+	}
+
+	// the selectors of a template are only known once it is rendered
+	zerokProbe, err := h.ZkCRDProbeHandler.RenderProbe(zerokProbe)
+	if err != nil {
+		return model.Scenario{}, err
+	}
+	workloads := zerokProbe.GetSpec().Workloads
+	keys := make([]string, 0, len(workloads))
+	for key := range workloads {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	allErrs := field.ErrorList{}
+	for _, key := range keys {
+		selector := workloads[key].Selector
+		if selector != nil && selector.Namespace != "" && !operatorv1alpha1.IsNamespaceAllowed(allowedNamespaces, selector.Namespace) {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "workloads").Key(key).Child("selector", "namespace"),
+				"must be one of the allowed namespaces"))
+		}
+	}
+	if len(allErrs) > 0 {
+		return model.Scenario{}, &translator.TranslationError{Errs: allErrs}
+	}
+	return h.ZkCRDProbeHandler.translate(zerokProbe)
+}
+
+// findProbe looks up the probe with the id in the path, the id of a probe is its uid. The error response is
+// written when the probe can not be found.
+func (h *ProbeApiHandler) findProbe(ctx iris.Context) (operatorv1alpha1.Probe, bool) {
+	probeId := ctx.Params().Get("id")
+
+	zerokProbes, err := h.listProbes(ctx.Request().Context())
+	if err != nil {
+		zklogger.Error(probeApiTag, "Error while listing probes ", err)
+		h.writeError(ctx, iris.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	for _, zerokProbe := range zerokProbes {
+		if translator.ScenarioId(zerokProbe) == probeId {
+			return zerokProbe, true
+		}
+	}
+
+	h.writeError(ctx, iris.StatusNotFound, "probe "+probeId+" not found")
+	return nil, false
+}
+
+func (h *ProbeApiHandler) listProbes(ctx context.Context) ([]operatorv1alpha1.Probe, error) {
+	return operatorv1alpha1.ListProbes(ctx, h.Reader)
+}
+
+func (h *ProbeApiHandler) toProbeResponse(zerokProbe operatorv1alpha1.Probe) ProbeResponse {
+	storedScenario := h.ZkCRDProbeHandler.GetStoredScenario(translator.ScenarioId(zerokProbe))
+	return ProbeResponse{
+		Id:              translator.ScenarioId(zerokProbe),
+		Kind:            zerokProbe.GetProbeKind(),
+		Namespace:       zerokProbe.GetNamespace(),
+		Name:            zerokProbe.GetName(),
+		Spec:            *zerokProbe.GetSpec(),
+		Status:          *zerokProbe.GetStatus(),
+		ScenarioVersion: scenarioVersion(storedScenario),
+		SyncStatus:      h.ZkCRDProbeHandler.GetScenarioSyncStatus(zerokProbe, storedScenario),
+	}
+}
+
+func (h *ProbeApiHandler) writeError(ctx iris.Context, statusCode int, message string) {
+	ctx.StatusCode(statusCode)
+	_ = ctx.JSON(probeApiError{Error: message})
+}
+
+// writeBodyError answers a request whose body can not be read, 413 when it is larger than maxProbeRequestBodySize.
+func (h *ProbeApiHandler) writeBodyError(ctx iris.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		h.writeError(ctx, iris.StatusRequestEntityTooLarge, fmt.Sprintf("the body is larger than %d bytes", maxBytesErr.Limit))
+		return
+	}
+	h.writeError(ctx, iris.StatusBadRequest, err.Error())
+}
+
+func scenarioVersion(scenario *model.Scenario) string {
+	if scenario == nil {
+		return ""
+	}
+	return scenario.Version
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris/v12"
+	operatorv1alpha1 "github.com/zerok-ai/zk-operator/api/v1alpha1"
+	"github.com/zerok-ai/zk-operator/internal/store"
+	"github.com/zerok-ai/zk-operator/internal/translator"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newProbeApi returns the routes of the probe api on a fake client holding probes, with the scenarios in
+// scenarioStore.
+func newProbeApi(t *testing.T, scenarioStore store.ScenarioStore, allowedNamespaces []string, probes ...operatorv1alpha1.Probe) *iris.Application {
+	scheme := runtime.NewScheme()
+	if err := operatorv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, probe := range probes {
+		builder = builder.WithObjects(probe)
+	}
+	fakeClient := builder.Build()
+
+	h := &ProbeApiHandler{}
+	h.Init(fakeClient, &ZkCRDProbeHandler{ScenarioStore: scenarioStore, TemplateReader: fakeClient, WorkloadReader: fakeClient,
+		AllowedNamespaces: allowedNamespaces})
+
+	app := iris.New()
+	app.Logger().SetLevel("disable")
+	app.Get("/v1/probes", h.ListProbes)
+	app.Get("/v1/probes/{id}", h.GetProbe)
+	app.Get("/v1/probes/{id}/scenario", h.GetProbeScenario)
+	app.Post("/v1/probes:translate", h.TranslateProbe)
+	if err := app.Build(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return app
+}
+
+func serve(app *iris.Application, method string, path string, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	app.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return recorder
+}
+
+func TestProbeApiReadsProbes(t *testing.T) {
+	storedProbe := newProbe(t, "stored", probeSpec)
+	unstoredProbe := newProbe(t, "unstored", probeSpec)
+	scenarioStore := store.NewMemoryScenarioStore()
+	if err := scenarioStore.Set("stored", translate(t, storedProbe)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	app := newProbeApi(t, scenarioStore, nil, storedProbe, unstoredProbe)
+
+	response := serve(app, http.MethodGet, "/v1/probes", "")
+	var probes []ProbeResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &probes); response.Code != http.StatusOK || err != nil || len(probes) != 2 {
+		t.Fatalf("expected the two probes, got %d %s", response.Code, response.Body.String())
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		statusCode int
+		syncStatus ScenarioSyncStatus
+	}{
+		{name: "stored probe", path: "/v1/probes/stored", statusCode: http.StatusOK, syncStatus: ScenarioSynced},
+		{name: "probe without a scenario", path: "/v1/probes/unstored", statusCode: http.StatusOK, syncStatus: ScenarioMissing},
+		{name: "unknown probe", path: "/v1/probes/unknown", statusCode: http.StatusNotFound},
+		{name: "scenario of a stored probe", path: "/v1/probes/stored/scenario", statusCode: http.StatusOK},
+		{name: "scenario of a probe without a scenario", path: "/v1/probes/unstored/scenario", statusCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := serve(app, http.MethodGet, tt.path, "")
+			if response.Code != tt.statusCode {
+				t.Fatalf("expected status %d, got %d %s", tt.statusCode, response.Code, response.Body.String())
+			}
+			if tt.syncStatus == "" {
+				return
+			}
+			var probe ProbeResponse
+			if err := json.Unmarshal(response.Body.Bytes(), &probe); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if probe.SyncStatus != tt.syncStatus {
+				t.Errorf("expected sync status %s, got %s", tt.syncStatus, probe.SyncStatus)
+			}
+		})
+	}
+}
+
+func TestProbeApiTranslatesProbesOfAllowedNamespaces(t *testing.T) {
+	selectorSpec := `
+title: errors
+enabled: true
+workloads:
+  OTEL/orders:
+    selector: {namespace: team-b, deployment: orders}
+    rule: {type: rule_group, condition: AND, rules: [{type: rule, id: http.status_code, datatype: integer, operator: equal, value: "404"}]}
+`
+	manifest := func(kind string, namespace string, spec string) string {
+		return "kind: " + kind + "\nmetadata: {name: errors, namespace: \"" + namespace + "\"}\nspec:" +
+			strings.ReplaceAll(spec, "\n", "\n  ")
+	}
+
+	tests := []struct {
+		name              string
+		allowedNamespaces []string
+		manifest          string
+		statusCode        int
+		errs              []string
+	}{
+		{name: "probe of an allowed namespace", allowedNamespaces: []string{"team-a"},
+			manifest: manifest("ZerokProbe", "team-a", probeSpec), statusCode: http.StatusOK},
+		{name: "probe of another namespace", allowedNamespaces: []string{"team-a"},
+			manifest: manifest("ZerokProbe", "team-b", probeSpec), statusCode: http.StatusUnprocessableEntity,
+			errs: []string{"metadata.namespace"}},
+		{name: "probe without a namespace", allowedNamespaces: []string{"team-a"},
+			manifest: manifest("ZerokProbe", "", probeSpec), statusCode: http.StatusUnprocessableEntity,
+			errs: []string{"metadata.namespace"}},
+		{name: "cluster probe", allowedNamespaces: []string{"team-a"},
+			manifest: manifest("ClusterZerokProbe", "", probeSpec), statusCode: http.StatusUnprocessableEntity,
+			errs: []string{"kind"}},
+		{name: "selector of another namespace", allowedNamespaces: []string{"team-a"},
+			manifest: manifest("ZerokProbe", "team-a", selectorSpec), statusCode: http.StatusUnprocessableEntity,
+			errs: []string{"spec.workloads[OTEL/orders].selector.namespace"}},
+		{name: "cluster probe without restricted namespaces",
+			manifest: manifest("ClusterZerokProbe", "", probeSpec), statusCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newProbeApi(t, store.NewMemoryScenarioStore(), tt.allowedNamespaces)
+
+			response := serve(app, http.MethodPost, "/v1/probes:translate", tt.manifest)
+			if response.Code != tt.statusCode {
+				t.Fatalf("expected status %d, got %d %s", tt.statusCode, response.Code, response.Body.String())
+			}
+			var result translator.TranslationResult
+			if err := json.Unmarshal(response.Body.Bytes(), &result); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Errors) != len(tt.errs) {
+				t.Fatalf("expected errors on %v, got %v", tt.errs, result.Errors)
+			}
+			for i, field := range tt.errs {
+				if !strings.HasPrefix(result.Errors[i], field+":") {
+					t.Errorf("expected an error on %s, got %s", field, result.Errors[i])
+				}
+			}
+		})
+	}
+}
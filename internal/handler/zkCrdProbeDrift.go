@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"errors"
+	operatorv1alpha1 "github.com/zerok-ai/zk-operator/api/v1alpha1"
+	promMetrics "github.com/zerok-ai/zk-operator/internal/metrics"
+	"github.com/zerok-ai/zk-operator/internal/store"
+	"github.com/zerok-ai/zk-operator/internal/translator"
+	logger "github.com/zerok-ai/zk-utils-go/logs"
+	"github.com/zerok-ai/zk-utils-go/scenario/model"
+	"time"
+)
+
+// ProbeDrift is the difference between the live ZerokProbes and ClusterZerokProbes and the scenarios found in redis.
+type ProbeDrift struct {
+	// Orphaned are the ids of scenarios written by the operator for which there is no active probe.
+	Orphaned []string
+	// Missing are the ids of active probes whose scenario is not in redis.
+	Missing []string
+	// Outdated are the ids of active probes whose scenario in redis differs from the translated spec.
+	Outdated []string
+}
+
+// ReconcileScenarios compares the scenarios in redis with the probes returned by listProbes, deletes the orphaned
+// scenarios and writes the missing or outdated ones. The drift found before the repair is returned and exported
+// as metrics.
+func (h *ZkCRDProbeHandler) ReconcileScenarios(listProbes func() ([]operatorv1alpha1.Probe, error)) (ProbeDrift, error) {
+	drift := ProbeDrift{}
+
+	// the probes are translated without holding storeMutex, so the reconciler is not blocked by a pass over all the
+	// probes. A repair is only written while the scenario is still as it was listed, see repairScenario.
+	// the scenarios are read before the probes are listed, so that the scenario of a probe created in between
+	// is not taken for an orphan. Only the scenarios written by the operator are listed, the store is shared with
+	// the rest of the stack.
+	storedScenarios, err := h.ScenarioStore.List()
+	if err != nil {
+		logger.Error(zkCRDProbeLog, "Error while listing scenarios for drift detection ", err)
+		return drift, err
+	}
+
+	zerokProbes, err := listProbes()
+	if err != nil {
+		logger.Error(zkCRDProbeLog, "Error while listing probes for drift detection ", err)
+		return drift, err
+	}
+
+	now := time.Now()
+	liveProbeIds := map[string]bool{}
+	expectedScenarios := map[string]model.Scenario{}
+	for _, zerokProbe := range zerokProbes {
+		probeId := translator.ScenarioId(zerokProbe)
+
+		// probes being deleted are taken care of by their finalizer
+		if !zerokProbe.GetDeletionTimestamp().IsZero() {
+			liveProbeIds[probeId] = true
+			continue
+		}
+		if !zerokProbe.GetSpec().Enabled || !operatorv1alpha1.IsProbeNamespaceAllowed(zerokProbe, h.AllowedNamespaces) ||
+			!operatorv1alpha1.IsWithinActiveWindow(zerokProbe.GetSpec(), now) {
+			continue
+		}
+		liveProbeIds[probeId] = true
+
+		// the reconciler has not processed the current generation of the probe yet
+		if zerokProbe.GetStatus().ObservedGeneration != zerokProbe.GetGeneration() {
+			continue
+		}
+
+		// a probe which can not be translated keeps the scenario of its last valid spec, see UpdateCRDProbe, while
+		// a probe whose selectors select no pods is inactive
+		scenario, err := h.translate(zerokProbe)
+		var noWorkloadsErr *translator.NoWorkloadsSelectedError
+		if errors.As(err, &noWorkloadsErr) {
+			delete(liveProbeIds, probeId)
+			continue
+		}
+		if err != nil {
+			logger.Debug(zkCRDProbeLog, "Skipping drift detection of probe ", zerokProbe.GetName(), " ", err)
+			continue
+		}
+		expectedScenarios[probeId] = scenario
+	}
+
+	for id := range storedScenarios {
+		if !liveProbeIds[id] {
+			drift.Orphaned = append(drift.Orphaned, id)
+		}
+	}
+	for id, scenario := range expectedScenarios {
+		storedScenario, ok := storedScenarios[id]
+		if !ok {
+			drift.Missing = append(drift.Missing, id)
+			continue
+		}
+		if !scenarioMatches(scenario, storedScenario) {
+			drift.Outdated = append(drift.Outdated, id)
+		}
+	}
+
+	promMetrics.OrphanedScenarios.Set(float64(len(drift.Orphaned)))
+	promMetrics.MissingScenarios.Set(float64(len(drift.Missing)))
+	promMetrics.OutdatedScenarios.Set(float64(len(drift.Outdated)))
+
+	var errs []error
+	for _, id := range drift.Orphaned {
+		storedScenario := storedScenarios[id]
+		err := h.repairScenario(id, &storedScenario, func() error {
+			logger.Info(zkCRDProbeLog, "Deleting orphaned scenario with id ", id, " from redis.")
+			_, err := h.ScenarioStore.Delete(id)
+			return err
+		})
+		if err != nil {
+			logger.Error(zkCRDProbeLog, "Error while deleting orphaned scenario id ", id, " from redis ", err)
+			errs = append(errs, err)
+		}
+	}
+	for _, id := range append(drift.Missing, drift.Outdated...) {
+		var listedScenario *model.Scenario
+		if storedScenario, ok := storedScenarios[id]; ok {
+			listedScenario = &storedScenario
+		}
+		err := h.repairScenario(id, listedScenario, func() error {
+			logger.Info(zkCRDProbeLog, "Rewriting drifted scenario with id ", id, " in redis.")
+			return h.ScenarioStore.Set(id, expectedScenarios[id])
+		})
+		if err != nil && !errors.Is(err, store.ErrLatest) {
+			logger.Error(zkCRDProbeLog, "Error while rewriting scenario id ", id, " in redis ", err)
+			errs = append(errs, err)
+		}
+	}
+	promMetrics.DriftRepairsFailed.Add(float64(len(errs)))
+
+	return drift, errors.Join(errs...)
+}
+
+// repairScenario runs repair while holding storeMutex, unless the scenario stored under id is no longer
+// listedScenario, nil for a missing one. The reconciler has then written or deleted it since the drift was detected,
+// and the translation of the drift detection may be of an older spec.
+func (h *ZkCRDProbeHandler) repairScenario(id string, listedScenario *model.Scenario, repair func() error) error {
+	h.storeMutex.Lock()
+	defer h.storeMutex.Unlock()
+
+	storedScenario, err := h.ScenarioStore.Get(id)
+	if err != nil {
+		return err
+	}
+	if (storedScenario == nil) != (listedScenario == nil) ||
+		(storedScenario != nil && (storedScenario.Version != listedScenario.Version || !scenarioMatches(*storedScenario, *listedScenario))) {
+		logger.Debug(zkCRDProbeLog, "Skipping repair of scenario id ", id, ", it changed since the drift was detected.")
+		return nil
+	}
+	return repair()
+}
+
+// ScenarioSyncStatus tells whether the scenario in redis matches the spec of a probe.
+type ScenarioSyncStatus string
+
+const (
+	ScenarioSynced    ScenarioSyncStatus = "Synced"
+	ScenarioOutOfSync ScenarioSyncStatus = "OutOfSync"
+	ScenarioMissing   ScenarioSyncStatus = "Missing"
+	ScenarioDisabled  ScenarioSyncStatus = "Disabled"
+	ScenarioInactive  ScenarioSyncStatus = "Inactive"
+	ScenarioInvalid   ScenarioSyncStatus = "Invalid"
+	ScenarioDeleting  ScenarioSyncStatus = "Deleting"
+)
+
+// GetStoredScenario returns the scenario stored in redis for the probe id, or nil if there is none.
+func (h *ZkCRDProbeHandler) GetStoredScenario(zkCRDProbeId string) *model.Scenario {
+	h.storeMutex.Lock()
+	defer h.storeMutex.Unlock()
+
+	scenario, err := h.ScenarioStore.Get(zkCRDProbeId)
+	if err != nil {
+		logger.Error(zkCRDProbeLog, "Error while reading scenario id ", zkCRDProbeId, " from the store ", err)
+		return nil
+	}
+	return scenario
+}
+
+// GetScenarioSyncStatus compares the scenario stored in redis for the probe with the translation of its spec.
+func (h *ZkCRDProbeHandler) GetScenarioSyncStatus(zerokProbe operatorv1alpha1.Probe, storedScenario *model.Scenario) ScenarioSyncStatus {
+	if !zerokProbe.GetDeletionTimestamp().IsZero() {
+		return ScenarioDeleting
+	}
+	if !zerokProbe.GetSpec().Enabled {
+		if storedScenario != nil {
+			return ScenarioOutOfSync
+		}
+		return ScenarioDisabled
+	}
+	if !operatorv1alpha1.IsWithinActiveWindow(zerokProbe.GetSpec(), time.Now()) {
+		if storedScenario != nil {
+			return ScenarioOutOfSync
+		}
+		return ScenarioInactive
+	}
+
+	scenario, err := h.translate(zerokProbe)
+	var noWorkloadsErr *translator.NoWorkloadsSelectedError
+	if errors.As(err, &noWorkloadsErr) {
+		if storedScenario != nil {
+			return ScenarioOutOfSync
+		}
+		return ScenarioInactive
+	}
+	if err != nil {
+		return ScenarioInvalid
+	}
+	if storedScenario == nil {
+		return ScenarioMissing
+	}
+	if !scenarioMatches(scenario, *storedScenario) {
+		return ScenarioOutOfSync
+	}
+	return ScenarioSynced
+}
+
+// scenarioMatches compares the content of two scenarios. The version is ignored, it carries the generation of the
+// probe, which also changes on edits of the spec that leave the scenario as it is.
+func scenarioMatches(scenario model.Scenario, storedScenario model.Scenario) bool {
+	scenario.Version = storedScenario.Version
+	return scenario.Equals(storedScenario)
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	operatorv1alpha1 "github.com/zerok-ai/zk-operator/api/v1alpha1"
+	"github.com/zerok-ai/zk-operator/internal/store"
+	"github.com/zerok-ai/zk-operator/internal/translator"
+	"github.com/zerok-ai/zk-utils-go/scenario/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+const probeSpec = `
+title: errors
+enabled: true
+workloads:
+  OTEL/orders:
+    rule: {type: rule_group, condition: AND, rules: [{type: rule, id: http.status_code, datatype: integer, operator: greater_than_equal, value: "400"}]}
+`
+
+// newProbe returns a probe of generation 1 processed by the reconciler, with the spec read from yaml.
+func newProbe(t *testing.T, uid string, spec string) *operatorv1alpha1.ZerokProbe {
+	probe := &operatorv1alpha1.ZerokProbe{
+		ObjectMeta: metav1.ObjectMeta{Name: uid, Namespace: "team-a", UID: types.UID(uid), Generation: 1},
+		Status:     operatorv1alpha1.ZerokProbeStatus{ObservedGeneration: 1},
+	}
+	if err := yaml.UnmarshalStrict([]byte(spec), &probe.Spec); err != nil {
+		t.Fatalf("invalid spec: %v", err)
+	}
+	return probe
+}
+
+func translate(t *testing.T, probe operatorv1alpha1.Probe) model.Scenario {
+	scenario, err := translator.TranslateZerokProbe(probe)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return scenario
+}
+
+func TestReconcileScenarios(t *testing.T) {
+	missingProbe := newProbe(t, "missing", probeSpec)
+	outdatedProbe := newProbe(t, "outdated", probeSpec)
+	syncedProbe := newProbe(t, "synced", probeSpec)
+	disabledProbe := newProbe(t, "disabled", probeSpec)
+	disabledProbe.Spec.Enabled = false
+
+	scenarioStore := store.NewMemoryScenarioStore()
+	outdatedScenario := translate(t, outdatedProbe)
+	outdatedScenario.Title = "old title"
+	for id, scenario := range map[string]model.Scenario{
+		"outdated": outdatedScenario,
+		"synced":   translate(t, syncedProbe),
+		"disabled": translate(t, disabledProbe),
+		"orphaned": translate(t, newProbe(t, "orphaned", probeSpec)),
+	} {
+		if err := scenarioStore.Set(id, scenario); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	h := &ZkCRDProbeHandler{ScenarioStore: scenarioStore}
+	drift, err := h.ReconcileScenarios(func() ([]operatorv1alpha1.Probe, error) {
+		return []operatorv1alpha1.Probe{missingProbe, outdatedProbe, syncedProbe, disabledProbe}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sort.Strings(drift.Orphaned)
+	expected := ProbeDrift{Orphaned: []string{"disabled", "orphaned"}, Missing: []string{"missing"}, Outdated: []string{"outdated"}}
+	if !reflect.DeepEqual(drift, expected) {
+		t.Errorf("expected drift %+v, got %+v", expected, drift)
+	}
+
+	scenarios, _ := scenarioStore.List()
+	var ids []string
+	for id := range scenarios {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	if !reflect.DeepEqual(ids, []string{"missing", "outdated", "synced"}) {
+		t.Errorf("expected the scenarios of the live probes to be stored, got %v", ids)
+	}
+	if scenarios["outdated"].Title != "errors" {
+		t.Errorf("expected the outdated scenario to be rewritten, got title %q", scenarios["outdated"].Title)
+	}
+}
+
+func TestRepairScenarioSkipsChangedScenarios(t *testing.T) {
+	listedScenario := translate(t, newProbe(t, "probe", probeSpec))
+	newerScenario := listedScenario
+	newerScenario.Version = "2"
+
+	tests := []struct {
+		name           string
+		storedScenario *model.Scenario
+		listedScenario *model.Scenario
+		repaired       bool
+	}{
+		{name: "unchanged scenario", storedScenario: &listedScenario, listedScenario: &listedScenario, repaired: true},
+		{name: "still missing scenario", repaired: true},
+		{name: "scenario written since", storedScenario: &listedScenario},
+		{name: "scenario deleted since", listedScenario: &listedScenario},
+		{name: "scenario updated since", storedScenario: &newerScenario, listedScenario: &listedScenario},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scenarioStore := store.NewMemoryScenarioStore()
+			if tt.storedScenario != nil {
+				if err := scenarioStore.Set("probe", *tt.storedScenario); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			h := &ZkCRDProbeHandler{ScenarioStore: scenarioStore}
+
+			repaired := false
+			if err := h.repairScenario("probe", tt.listedScenario, func() error { repaired = true; return nil }); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if repaired != tt.repaired {
+				t.Errorf("expected repaired to be %t, got %t", tt.repaired, repaired)
+			}
+		})
+	}
+}
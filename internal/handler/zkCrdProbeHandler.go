@@ -1,242 +1,357 @@
 package handler
 
 import (
+	"context"
 	"errors"
-	"fmt"
 	operatorv1alpha1 "github.com/zerok-ai/zk-operator/api/v1alpha1"
-	"github.com/zerok-ai/zk-operator/internal/common"
 	"github.com/zerok-ai/zk-operator/internal/config"
 	promMetrics "github.com/zerok-ai/zk-operator/internal/metrics"
+	"github.com/zerok-ai/zk-operator/internal/resolver"
+	"github.com/zerok-ai/zk-operator/internal/store"
+	"github.com/zerok-ai/zk-operator/internal/translator"
 	logger "github.com/zerok-ai/zk-utils-go/logs"
 	"github.com/zerok-ai/zk-utils-go/scenario/model"
-	zkredis "github.com/zerok-ai/zk-utils-go/storage/redis"
-	dbNames "github.com/zerok-ai/zk-utils-go/storage/redis/clientDBNames"
-	"strconv"
-	"strings"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"math"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sync"
 	"time"
 )
 
 var zkCRDProbeLog = "ZkCrdProbeHandler"
 
+const (
+	templateRequestTimeout = 10 * time.Second
+	workloadRequestTimeout = 10 * time.Second
+)
+
 type ZkCRDProbeHandler struct {
-	VersionedStore   *zkredis.VersionedStore[model.Scenario]
-	latestUpdateTime string
+	ScenarioStore store.ScenarioStore
+	// TemplateReader reads the ZerokProbeTemplates the probes are rendered from, usually the cached client of the
+	// manager.
+	TemplateReader client.Reader
+	// WorkloadReader reads the pods and deployments selected by the workloads of the probes, usually the cached
+	// client of the manager.
+	WorkloadReader client.Reader
+	// AllowedNamespaces are the namespaces ZerokProbes may be live in, every namespace is allowed when it is empty.
+	AllowedNamespaces []string
+	latestUpdateTime  string
+	// storeMutex serializes the writes of the reconciler with the drift detection
+	storeMutex sync.Mutex
+
+	pingTimeout     time.Duration
+	healthMutex     sync.Mutex
+	lastHealthError error
 }
 
 func (h *ZkCRDProbeHandler) Init(cfg config.ZkOperatorConfig) error {
-	store, err := zkredis.GetVersionedStore[model.Scenario](&cfg.Redis, dbNames.ScenariosDBName, common.RedisSyncInterval)
+	scenarioStore, err := store.NewScenarioStore(cfg)
 	if err != nil {
 		return err
 	}
-	h.VersionedStore = store
+	h.ScenarioStore = scenarioStore
+	h.AllowedNamespaces = cfg.Probes.AllowedNamespaces
+	promMetrics.RegisterStoredScenarios(h.countStoredScenarios)
 	h.latestUpdateTime = "0"
+	h.pingTimeout = time.Duration(cfg.Store.PingTimeout) * time.Second
 
 	return nil
 }
 
-func (h *ZkCRDProbeHandler) CreateCRDProbe(zerokProbe *operatorv1alpha1.ZerokProbe) (string, error) {
+// CreateCRDProbe stores the scenario of a probe processed for the first time and returns the hash of the stored
+// scenario, which is empty for a disabled probe or a probe outside of its active window. A probe whose selectors
+// select no pods is not stored either, the *translator.NoWorkloadsSelectedError is returned for it.
+func (h *ZkCRDProbeHandler) CreateCRDProbe(zerokProbe operatorv1alpha1.Probe) (string, error) {
+	h.storeMutex.Lock()
+	defer h.storeMutex.Unlock()
+
 	logger.Debug(zkCRDProbeLog, "New CRD created")
-	zkProbe := constructRedisProbeStructureFromCRD(zerokProbe)
+	zkProbe, err := h.translate(zerokProbe)
+	var noWorkloadsErr *translator.NoWorkloadsSelectedError
+	if errors.As(err, &noWorkloadsErr) {
+		logger.Debug(zkCRDProbeLog, "Probe is Created without selected workloads, not storing in redis")
+		if deleteErr := h.deleteScenarioOfInactiveProbe(zerokProbe); deleteErr != nil {
+			return "", deleteErr
+		}
+		return "", err
+	}
+	if err != nil {
+		logger.Error(zkCRDProbeLog, "Error while translating crd probe ", zerokProbe.GetSpec().Title, " ", err)
+		recordTranslationFailure(err)
+		return "", err
+	}
 	//check if zkProbe is enabled to false delete from redis
 	if !zkProbe.Enabled {
 		logger.Debug(zkCRDProbeLog, "Probe is Created with enable false, not processing and storing in redis")
-	} else {
-		err := h.VersionedStore.SetValue(zkProbe.Id, zkProbe)
-		if err != nil {
-			if errors.Is(err, zkredis.LATEST) {
-				logger.Info(zkCRDProbeLog, "Latest value is already present in redis for crd probe Id ", zkProbe.Id)
-			} else {
-				logger.Error(zkCRDProbeLog, "Error while storing crd probe in redis ", err)
-				return "", err
-			}
+		return "", nil
+	}
+	if !operatorv1alpha1.IsWithinActiveWindow(zerokProbe.GetSpec(), time.Now()) {
+		logger.Debug(zkCRDProbeLog, "Probe is Created outside of its active window, not storing in redis")
+		return "", nil
+	}
+	scenarioHash := translator.ScenarioHash(zkProbe)
+	if h.isScenarioUnchanged(zerokProbe, scenarioHash) {
+		logger.Debug(zkCRDProbeLog, "Scenario of crd probe Id ", zkProbe.Id, " is unchanged, skipping write")
+		return scenarioHash, nil
+	}
+	err = h.ScenarioStore.Set(zkProbe.Id, zkProbe)
+	if err != nil {
+		if errors.Is(err, store.ErrLatest) {
+			logger.Info(zkCRDProbeLog, "Latest value is already present in redis for crd probe Id ", zkProbe.Id)
+		} else {
+			logger.Error(zkCRDProbeLog, "Error while storing crd probe in redis ", err)
+			return "", err
 		}
+	} else {
+		promMetrics.TotalProbesCreated.Inc()
 	}
-	promMetrics.TotalProbesCreated.Inc()
 	logger.Info(zkCRDProbeLog, "Successfully created new Probe with title ", zkProbe.Title)
-	return "", nil
+	return scenarioHash, nil
 }
 
 func (h *ZkCRDProbeHandler) DeleteCRDProbe(zkCRDProbeId string) (string, error) {
-	err := h.VersionedStore.Delete(zkCRDProbeId)
+	h.storeMutex.Lock()
+	defer h.storeMutex.Unlock()
+
+	return h.deleteScenario(zkCRDProbeId)
+}
+
+func (h *ZkCRDProbeHandler) deleteScenario(zkCRDProbeId string) (string, error) {
+	deleted, err := h.ScenarioStore.Delete(zkCRDProbeId)
 	if err != nil {
 		logger.Error(zkCRDProbeLog, "Error while deleting crd probe id ", zkCRDProbeId, " from redis ", err)
 		return "", err
 	}
-	promMetrics.TotalProbesDeleted.Inc()
+	if deleted {
+		promMetrics.TotalProbesDeleted.Inc()
+	}
 	logger.Info(zkCRDProbeLog, "Successfully Deleted Probe with id ", zkCRDProbeId, " from redis.")
 	return "", nil
 }
 
-func (h *ZkCRDProbeHandler) UpdateCRDProbe(zerokProbe *operatorv1alpha1.ZerokProbe) (string, error) {
+// UpdateCRDProbe stores the scenario of the current spec of a probe, or deletes it for a disabled probe or a probe
+// outside of its active window, and returns the hash of the stored scenario. The store is not written when the
+// scenario is unchanged since the last reconcile. The scenario of a probe whose selectors select no pods is deleted
+// too, the *translator.NoWorkloadsSelectedError is returned for it.
+func (h *ZkCRDProbeHandler) UpdateCRDProbe(zerokProbe operatorv1alpha1.Probe) (string, error) {
+	h.storeMutex.Lock()
+	defer h.storeMutex.Unlock()
+
 	logger.Debug(zkCRDProbeLog, "CRD updated")
-	zkProbe := constructRedisProbeStructureFromCRD(zerokProbe)
-	//check if zkProbe is enabled to false delete from redis
-	if !zkProbe.Enabled {
-		logger.Debug(zkCRDProbeLog, "Probe is disabled, deleting from redis")
-		_, err := h.DeleteCRDProbe(zkProbe.Id)
-		if err != nil {
-			logger.Error(zkCRDProbeLog, "Error while deleting crd probe id ", zkProbe.Id, " from redis ", err)
-			return "", err
+	zkProbe, err := h.translate(zerokProbe)
+	var noWorkloadsErr *translator.NoWorkloadsSelectedError
+	if errors.As(err, &noWorkloadsErr) {
+		logger.Debug(zkCRDProbeLog, "Probe selects no workloads, deleting from redis")
+		if deleteErr := h.deleteScenarioOfInactiveProbe(zerokProbe); deleteErr != nil {
+			return "", deleteErr
 		}
-		logger.Info(zkCRDProbeLog, "Successfully Deleted Probe with id ", zkProbe.Id, " from redis.")
-		return "", nil
+		return "", err
 	}
-	err := h.VersionedStore.SetValue(zkProbe.Id, zkProbe)
 	if err != nil {
-		if errors.Is(err, zkredis.LATEST) {
+		// the scenario stored for the previous generation of the probe is left untouched
+		logger.Error(zkCRDProbeLog, "Error while translating crd probe ", zerokProbe.GetSpec().Title, " ", err)
+		recordTranslationFailure(err)
+		if deleteErr := h.deleteScenarioOfDisallowedNamespace(zerokProbe); deleteErr != nil {
+			return "", deleteErr
+		}
+		return "", err
+	}
+	//check if zkProbe is enabled to false or is outside of its active window delete from redis
+	if !zkProbe.Enabled || !operatorv1alpha1.IsWithinActiveWindow(zerokProbe.GetSpec(), time.Now()) {
+		logger.Debug(zkCRDProbeLog, "Probe is inactive, deleting from redis")
+		return "", h.deleteScenarioOfInactiveProbe(zerokProbe)
+	}
+	scenarioHash := translator.ScenarioHash(zkProbe)
+	if h.isScenarioUnchanged(zerokProbe, scenarioHash) {
+		logger.Debug(zkCRDProbeLog, "Scenario of crd probe Id ", zkProbe.Id, " is unchanged, skipping write")
+		return scenarioHash, nil
+	}
+	err = h.ScenarioStore.Set(zkProbe.Id, zkProbe)
+	if err != nil {
+		if errors.Is(err, store.ErrLatest) {
 			logger.Info(zkCRDProbeLog, "Latest value is already present in redis for crd probe Id ", zkProbe.Id)
 		} else {
 			logger.Error(zkCRDProbeLog, "Error while storing crd probe in redis ", err)
 			return "", err
 		}
+	} else {
+		promMetrics.TotalProbesUpdated.Inc()
 	}
-	promMetrics.TotalProbesUpdated.Inc()
 	logger.Info(zkCRDProbeLog, "Successfully updated Probe with title ", zkProbe.Title, " from redis.")
-	return "", nil
+	return scenarioHash, nil
 }
 
-func (h *ZkCRDProbeHandler) CleanUpOnKill() error {
-	logger.Debug(zkCRDProbeLog, "Kill method in scenario rules.")
-	return nil
-}
+// translate checks that the namespace of a probe is allowed, renders the spec of a probe which refers to a
+// ZerokProbeTemplate, resolves the selectors of its workloads and translates the probe into a scenario. A disallowed
+// namespace or a missing template is reported as a *translator.TranslationError, like any other invalid spec.
+func (h *ZkCRDProbeHandler) translate(zerokProbe operatorv1alpha1.Probe) (model.Scenario, error) {
+	if errs := operatorv1alpha1.ValidateProbeNamespace(zerokProbe, h.AllowedNamespaces); len(errs) > 0 {
+		return model.Scenario{}, &translator.TranslationError{Errs: errs}
+	}
+	zerokProbe, err := h.RenderProbe(zerokProbe)
+	if err != nil {
+		return model.Scenario{}, err
+	}
+	if !resolver.HasWorkloadSelectors(zerokProbe.GetSpec()) {
+		return translator.TranslateZerokProbe(zerokProbe)
+	}
 
-func (h *ZkCRDProbeHandler) IsHealthy() bool {
-	return true
+	workloadServices, err := h.resolveWorkloadServices(zerokProbe)
+	if err != nil {
+		return model.Scenario{}, err
+	}
+	return translator.TranslateZerokProbeWithServices(zerokProbe, workloadServices)
 }
 
-func constructRedisProbeStructureFromCRD(zerokProbe *operatorv1alpha1.ZerokProbe) model.Scenario {
-
-	zkProbeScenario := model.Scenario{}
-	defer func() {
-		if r := recover(); r != nil {
-			logger.Error(zkCRDProbeLog, "Error in constructing probe from CRD", r)
-			zkProbeScenario = model.Scenario{}
-		}
-	}()
-
-	var zerokProbeWorkloadsMap map[string]model.Workload
-	var zerokServiceWorkloadMap map[string]string
-	zkProbeScenario.Enabled = zerokProbe.Spec.Enabled
-	zkProbeScenario.Version = strconv.FormatInt(time.Now().Unix(), 10)
-	zkProbeScenario.Id = string(zerokProbe.GetUID())
-	zkProbeScenario.Title = zerokProbe.Spec.Title
-	zkProbeScenario.Type = "SYSTEM"
-	zerokProbeWorkloadsMap, zerokServiceWorkloadMap = getZerokProbeWorkloadsFromCrd(zerokProbe.Spec.Workloads)
-	zkProbeScenario.Workloads = &zerokProbeWorkloadsMap
-	zkProbeScenario.RateLimit = getZerokProbeRateLimitFromCrd(zerokProbe.Spec.RateLimit)
-	zkProbeScenario.Filter = getZerokProbeFiltersFromCrdFilters(zerokProbe.Spec.Filter, zerokServiceWorkloadMap)
-	zkProbeScenario.GroupBy = getZerokProbeGroupByFromCrd(&zerokProbe.Spec.GroupBy, zerokServiceWorkloadMap)
-	return zkProbeScenario
-}
-
-func getZerokProbeWorkloadsFromCrd(crdWorkloadsMap map[string]operatorv1alpha1.Workload) (map[string]model.Workload, map[string]string) {
-	zerokProbeWorkloadsMap := make(map[string]model.Workload)
-	zerokServiceWorkloadMap := make(map[string]string)
-	for key, value := range crdWorkloadsMap {
-		probeZerokWorkload := model.Workload{}
-		executor, serviceName, err := getExecutorAndServiceNameFromKey(key)
-		if err != nil {
-			return nil, nil
-		}
-		probeZerokWorkload.Service = serviceName
-		probeZerokWorkload.Rule = value.Rule
-		probeZerokWorkload.TraceRole = "server"
-		probeZerokWorkload.Protocol = "HTTP"
-		probeZerokWorkload.Executor = model.ExecutorName(executor)
-		workloadId := model.WorkLoadUUID(probeZerokWorkload).String()
-		zerokProbeWorkloadsMap[workloadId] = probeZerokWorkload
-		zerokServiceWorkloadMap[serviceName] = workloadId
+// RenderProbe returns the probe rendered from its ZerokProbeTemplate, or the probe itself when it does not use one.
+func (h *ZkCRDProbeHandler) RenderProbe(zerokProbe operatorv1alpha1.Probe) (operatorv1alpha1.Probe, error) {
+	templateRef := zerokProbe.GetSpec().Template
+	if templateRef == nil {
+		return zerokProbe, nil
+	}
+	template, err := h.getTemplate(templateRef.Name)
+	if err != nil {
+		return nil, err
 	}
-	return zerokProbeWorkloadsMap, zerokServiceWorkloadMap
+	return translator.RenderZerokProbe(zerokProbe, template)
 }
 
-func getExecutorAndServiceNameFromKey(workloadKey string) (string, string, error) {
-	parts := strings.Split(workloadKey, "/")
-
-	if len(parts) != 2 {
-		return "", "", errors.New("invalid input format")
+func (h *ZkCRDProbeHandler) resolveWorkloadServices(zerokProbe operatorv1alpha1.Probe) (translator.WorkloadServices, error) {
+	if h.WorkloadReader == nil {
+		return nil, errors.New("workload selectors can not be resolved, no reader is set")
 	}
 
-	executor := operatorv1alpha1.ExecutorType(parts[0])
-	switch executor {
-	case operatorv1alpha1.OTEL:
-		// Valid executor
-	default:
-		return "", "", errors.New(fmt.Sprintf("invalid executor:%s provided in workload key", executor))
+	ctx, cancel := context.WithTimeout(context.Background(), workloadRequestTimeout)
+	defer cancel()
+
+	return resolver.ResolveWorkloadServices(ctx, h.WorkloadReader, zerokProbe)
+}
+
+func (h *ZkCRDProbeHandler) getTemplate(name string) (*operatorv1alpha1.ZerokProbeTemplate, error) {
+	if h.TemplateReader == nil {
+		return nil, errors.New("templates can not be read, no reader is set")
 	}
 
-	serviceName := parts[1]
-	return string(executor), serviceName, nil
+	ctx, cancel := context.WithTimeout(context.Background(), templateRequestTimeout)
+	defer cancel()
+
+	template := &operatorv1alpha1.ZerokProbeTemplate{}
+	err := h.TemplateReader.Get(ctx, client.ObjectKey{Name: name}, template)
+	if apierrors.IsNotFound(err) {
+		return nil, &translator.TranslationError{Errs: field.ErrorList{field.NotFound(field.NewPath("spec", "template", "name"), name)}}
+	}
+	if err != nil {
+		logger.Error(zkCRDProbeLog, "Error while reading template ", name, " ", err)
+		return nil, err
+	}
+	return template, nil
 }
 
-func getZerokProbeRateLimitFromCrd(crdRateLimitList []operatorv1alpha1.RateLimit) []model.RateLimit {
-	if crdRateLimitList == nil {
-		return []model.RateLimit{
-			{BucketMaxSize: 5, BucketRefillSize: 5, TickDuration: "1m"},
-		}
+// deleteScenarioOfDisallowedNamespace deletes the scenario of a probe whose namespace is no longer allowed by the
+// operator config. Unlike the scenario of an invalid spec, it must not stay in place.
+func (h *ZkCRDProbeHandler) deleteScenarioOfDisallowedNamespace(zerokProbe operatorv1alpha1.Probe) error {
+	if operatorv1alpha1.IsProbeNamespaceAllowed(zerokProbe, h.AllowedNamespaces) {
+		return nil
 	}
-	probeZerokRateLimitList := make([]model.RateLimit, 0)
-	for _, crdRateLimit := range crdRateLimitList {
-		probeZerokRateLimitList = append(probeZerokRateLimitList, model.RateLimit{TickDuration: crdRateLimit.TickDuration, BucketMaxSize: crdRateLimit.BucketMaxSize, BucketRefillSize: crdRateLimit.BucketRefillSize})
+	probeId := translator.ScenarioId(zerokProbe)
+	storedScenario, err := h.ScenarioStore.Get(probeId)
+	if err != nil || storedScenario == nil {
+		return err
 	}
-	return probeZerokRateLimitList
+	logger.Info(zkCRDProbeLog, "Namespace ", zerokProbe.GetNamespace(), " is not allowed, deleting scenario of crd probe Id ", probeId)
+	_, err = h.deleteScenario(probeId)
+	return err
 }
 
-func getZerokProbeGroupByFromCrd(crdGroupByList *[]operatorv1alpha1.GroupBy, zerokServiceWorkloadMap map[string]string) []model.GroupBy {
-	if crdGroupByList == nil {
+// deleteScenarioOfInactiveProbe deletes the scenario of a probe which is disabled, outside of its active window or
+// selects no pods. Nothing is written when the last reconcile already found the probe inactive.
+func (h *ZkCRDProbeHandler) deleteScenarioOfInactiveProbe(zerokProbe operatorv1alpha1.Probe) error {
+	probeId := translator.ScenarioId(zerokProbe)
+	if h.isScenarioUnchanged(zerokProbe, "") {
+		logger.Debug(zkCRDProbeLog, "Probe is inactive and not in redis, skipping delete")
 		return nil
 	}
-	var probeZerokGroupByList []model.GroupBy
-	for _, crdGroupBy := range *crdGroupByList {
-		groupBy := &crdGroupBy
-		workloadKey := zerokServiceWorkloadMap[groupBy.WorkloadKey]
-		probeZerokGroupByList = append(probeZerokGroupByList, model.GroupBy{WorkloadId: workloadKey, Title: groupBy.Title, Hash: groupBy.Hash})
+	_, err := h.deleteScenario(probeId)
+	if err != nil {
+		logger.Error(zkCRDProbeLog, "Error while deleting crd probe id ", probeId, " from redis ", err)
+		return err
 	}
-	return probeZerokGroupByList
+	logger.Info(zkCRDProbeLog, "Successfully Deleted Probe with id ", probeId, " from redis.")
+	return nil
 }
 
-func getZerokProbeFiltersFromCrdFilters(crdFilter operatorv1alpha1.Filter, zerokServiceWorkloadMap map[string]string) model.Filter {
-	var workloadIdList model.WorkloadIds
-	var probeZerokFilter model.Filter
-	if crdFilter.WorkloadKeys == nil || crdFilter.Filters == nil {
-		for _, value := range zerokServiceWorkloadMap {
-			workloadIdList = append(workloadIdList, value)
-		}
-		return model.Filter{
-			Type:        "workload",
-			Condition:   "AND",
-			Filters:     nil,
-			WorkloadIds: &workloadIdList,
-		}
+// isScenarioUnchanged tells whether the scenario with scenarioHash is the one recorded in the status of the probe
+// by the last reconcile. An empty hash stands for no scenario. The store is still checked, so that a scenario lost
+// from the store is written again.
+func (h *ZkCRDProbeHandler) isScenarioUnchanged(zerokProbe operatorv1alpha1.Probe, scenarioHash string) bool {
+	if zerokProbe.GetStatus().ScenarioHash != scenarioHash {
+		return false
 	}
-	//iterate over the services in filter and update them with workload id
-	// Check if WorkloadIds is not nil before iterating
-	if crdFilter.WorkloadKeys != nil {
-		// Iterate over WorkloadIds
-		for _, serviceId := range *crdFilter.WorkloadKeys {
-			workloadIdList = append(workloadIdList, zerokServiceWorkloadMap[serviceId])
-		}
-		probeZerokFilter.WorkloadIds = &workloadIdList
+	storedScenario, err := h.ScenarioStore.Get(translator.ScenarioId(zerokProbe))
+	if err != nil {
+		return false
 	}
-	if crdFilter.Filters != nil {
-		var newFilters model.Filters
-		for _, filter := range *crdFilter.Filters {
-			newFilters = append(newFilters, getZerokProbeFiltersFromCrdFilters(filter, zerokServiceWorkloadMap))
-		}
-		probeZerokFilter.Filters = &newFilters
+	return (storedScenario != nil) == (scenarioHash != "")
+}
+
+// countStoredScenarios is called on every scrape of the metrics, NaN is exported when the store can not be read.
+func (h *ZkCRDProbeHandler) countStoredScenarios() float64 {
+	scenarios, err := h.ScenarioStore.List()
+	if err != nil {
+		return math.NaN()
 	}
-	//TODO:: throw error if both workloadIds and filters are nil
-	if crdFilter.Type != "" {
-		probeZerokFilter.Type = crdFilter.Type
-	} else {
-		probeZerokFilter.Type = "workload"
+	return float64(len(scenarios))
+}
+
+// recordTranslationFailure counts a failed translation once for every type of field error found in the probe.
+func recordTranslationFailure(err error) {
+	var translationErr *translator.TranslationError
+	if !errors.As(err, &translationErr) {
+		promMetrics.TranslationFailures.WithLabelValues("Unknown").Inc()
+		return
 	}
+	reasons := map[field.ErrorType]bool{}
+	for _, fieldErr := range translationErr.Errs {
+		reasons[fieldErr.Type] = true
+	}
+	for reason := range reasons {
+		promMetrics.TranslationFailures.WithLabelValues(string(reason)).Inc()
+	}
+}
 
-	//TODO:: throw error if condition is NULL or empty
-	if probeZerokFilter.Condition != "" {
-		probeZerokFilter.Condition = model.Condition(crdFilter.Condition)
-	} else {
-		probeZerokFilter.Condition = "AND"
+func (h *ZkCRDProbeHandler) CleanUpOnKill() error {
+	logger.Debug(zkCRDProbeLog, "Kill method in scenario rules.")
+	return h.ScenarioStore.Close()
+}
+
+// IsHealthy pings the scenario store, the handler is healthy when the store answers within the ping timeout.
+func (h *ZkCRDProbeHandler) IsHealthy() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), h.pingTimeout)
+	defer cancel()
+
+	err := h.ScenarioStore.Ping(ctx)
+	if err != nil {
+		logger.Error(zkCRDProbeLog, "Error while pinging the scenario store ", err)
+	}
+
+	h.healthMutex.Lock()
+	defer h.healthMutex.Unlock()
+	h.lastHealthError = err
+	return err == nil
+}
+
+func (h *ZkCRDProbeHandler) LastError() error {
+	h.healthMutex.Lock()
+	defer h.healthMutex.Unlock()
+	return h.lastHealthError
+}
+
+// ReadyzCheck is the readiness check of the manager, it fails while the scenario store can not be reached.
+func (h *ZkCRDProbeHandler) ReadyzCheck(_ *http.Request) error {
+	if !h.IsHealthy() {
+		return h.LastError()
 	}
-	return probeZerokFilter
+	return nil
 }
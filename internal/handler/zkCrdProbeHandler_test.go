@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	promMetrics "github.com/zerok-ai/zk-operator/internal/metrics"
+	"github.com/zerok-ai/zk-operator/internal/store"
+	"github.com/zerok-ai/zk-operator/internal/translator"
+)
+
+func TestDeleteScenarioCountsOnlyStoredScenarios(t *testing.T) {
+	scenarioStore := store.NewMemoryScenarioStore()
+	if err := scenarioStore.Set("stored", translate(t, newProbe(t, "stored", probeSpec))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	h := &ZkCRDProbeHandler{ScenarioStore: scenarioStore}
+
+	deleted := testutil.ToFloat64(promMetrics.TotalProbesDeleted)
+	for _, id := range []string{"stored", "stored", "missing"} {
+		if _, err := h.DeleteCRDProbe(id); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if count := testutil.ToFloat64(promMetrics.TotalProbesDeleted) - deleted; count != 1 {
+		t.Errorf("expected one delete to be counted, got %v", count)
+	}
+	if scenario, _ := scenarioStore.Get("stored"); scenario != nil {
+		t.Errorf("expected the scenario to be deleted, got %v", scenario)
+	}
+}
+
+func TestIsScenarioUnchanged(t *testing.T) {
+	scenario := translate(t, newProbe(t, "probe", probeSpec))
+	scenarioHash := translator.ScenarioHash(scenario)
+
+	tests := []struct {
+		name         string
+		statusHash   string
+		stored       bool
+		scenarioHash string
+		unchanged    bool
+	}{
+		{name: "stored scenario of the recorded hash", statusHash: scenarioHash, stored: true, scenarioHash: scenarioHash, unchanged: true},
+		{name: "scenario of another hash", statusHash: "previous", stored: true, scenarioHash: scenarioHash},
+		{name: "scenario lost from the store", statusHash: scenarioHash, scenarioHash: scenarioHash},
+		{name: "probe without a scenario", unchanged: true},
+		{name: "scenario left in the store of a probe without one", stored: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scenarioStore := store.NewMemoryScenarioStore()
+			if tt.stored {
+				if err := scenarioStore.Set("probe", scenario); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			probe := newProbe(t, "probe", probeSpec)
+			probe.Status.ScenarioHash = tt.statusHash
+			h := &ZkCRDProbeHandler{ScenarioStore: scenarioStore}
+
+			if unchanged := h.isScenarioUnchanged(probe, tt.scenarioHash); unchanged != tt.unchanged {
+				t.Errorf("expected unchanged to be %t, got %t", tt.unchanged, unchanged)
+			}
+		})
+	}
+}
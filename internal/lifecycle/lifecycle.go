@@ -0,0 +1,45 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"github.com/kataras/iris/v12"
+	"github.com/zerok-ai/zk-operator/internal"
+	"github.com/zerok-ai/zk-operator/internal/utils"
+	logger "github.com/zerok-ai/zk-utils-go/logs"
+	"time"
+)
+
+var LOG_TAG = "Lifecycle"
+
+// Lifecycle stops the parts of the operator which are not run by the controller-runtime manager.
+type Lifecycle struct {
+	App                 *iris.Application
+	Modules             []internal.ZkOperatorModule
+	HttpShutdownTimeout time.Duration
+}
+
+// Shutdown is called once the manager has stopped, i.e. after the in-flight reconciles are drained. The http server
+// is shut down first, so that no request reads from a module which is already cleaned up.
+func (l *Lifecycle) Shutdown() error {
+	var errs []error
+
+	logger.Info(LOG_TAG, "Shutting down http server.")
+	ctx, cancel := context.WithTimeout(context.Background(), l.HttpShutdownTimeout)
+	defer cancel()
+	if err := l.App.Shutdown(ctx); err != nil {
+		logger.Error(LOG_TAG, "Error while shutting down http server ", err)
+		errs = append(errs, err)
+	}
+
+	for _, module := range l.Modules {
+		moduleName := utils.GetTypeName(module)
+		logger.Info(LOG_TAG, "Cleaning up module ", moduleName)
+		if err := module.CleanUpOnKill(); err != nil {
+			logger.Error(LOG_TAG, "Error while cleaning up module ", moduleName, " ", err)
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	operatorv1alpha1 "github.com/zerok-ai/zk-operator/api/v1alpha1"
+	logger "github.com/zerok-ai/zk-utils-go/logs"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"strconv"
+	"time"
+)
+
+var probeCollectorLogTag = "ProbeCollector"
+
+const probeListTimeout = 5 * time.Second
+
+var probesDesc = prometheus.NewDesc(
+	"zerok_probes",
+	"number of ZerokProbes and ClusterZerokProbes by kind, phase and enabled state.",
+	[]string{"kind", "phase", "enabled"}, nil,
+)
+
+// ProbeCollector counts the probes of both kinds by phase and enabled state when the metrics are scraped, so the
+// numbers can not go stale when a reconcile is missed.
+type ProbeCollector struct {
+	Reader client.Reader
+}
+
+var _ prometheus.Collector = &ProbeCollector{}
+
+// RegisterProbeCollector registers a ProbeCollector listing the probes with reader, usually the cached client of
+// the manager.
+func RegisterProbeCollector(reader client.Reader) error {
+	return ctrlmetrics.Registry.Register(&ProbeCollector{Reader: reader})
+}
+
+func (c *ProbeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- probesDesc
+}
+
+func (c *ProbeCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), probeListTimeout)
+	defer cancel()
+
+	probes, err := operatorv1alpha1.ListProbes(ctx, c.Reader)
+	if err != nil {
+		logger.Error(probeCollectorLogTag, "Error while listing probes for metrics ", err)
+		return
+	}
+
+	type probeLabels struct {
+		kind    string
+		phase   string
+		enabled string
+	}
+	counts := map[probeLabels]int{}
+	for _, zerokProbe := range probes {
+		phase := zerokProbe.GetStatus().Phase
+		if phase == "" {
+			phase = operatorv1alpha1.ProbePending
+		}
+		counts[probeLabels{kind: zerokProbe.GetProbeKind(), phase: string(phase), enabled: strconv.FormatBool(zerokProbe.GetSpec().Enabled)}]++
+	}
+	for labels, count := range counts {
+		ch <- prometheus.MustNewConstMetric(probesDesc, prometheus.GaugeValue, float64(count), labels.kind, labels.phase, labels.enabled)
+	}
+}
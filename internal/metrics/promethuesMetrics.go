@@ -3,24 +3,87 @@ package metrics
 import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
+// the metrics are registered with the registry of controller-runtime, which is served both on the metrics endpoint
+// of the manager and on /metrics of the http server
+var factory = promauto.With(ctrlmetrics.Registry)
+
 var (
 	//total number of CRD's created
-	TotalProbesCreated = promauto.NewCounter(prometheus.CounterOpts{
+	TotalProbesCreated = factory.NewCounter(prometheus.CounterOpts{
 		Name: "zerok_crd_created_total",
 		Help: "total number of CRD's created.",
 	})
 
 	//total number of CRD's updated
-	TotalProbesUpdated = promauto.NewCounter(prometheus.CounterOpts{
+	TotalProbesUpdated = factory.NewCounter(prometheus.CounterOpts{
 		Name: "zerok_crd_updated_total",
 		Help: "total number of CRD's updated.",
 	})
 
 	//total number of CRD's deleted
-	TotalProbesDeleted = promauto.NewCounter(prometheus.CounterOpts{
+	TotalProbesDeleted = factory.NewCounter(prometheus.CounterOpts{
 		Name: "zerok_crd_deleted_total",
 		Help: "total number of CRD's deleted.",
 	})
+
+	//number of scenarios in redis without an enabled probe, found by the last drift detection
+	OrphanedScenarios = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "zerok_probe_drift_orphaned_scenarios",
+		Help: "number of scenarios in redis without an enabled probe, found by the last drift detection.",
+	})
+
+	//number of enabled probes without a scenario in redis, found by the last drift detection
+	MissingScenarios = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "zerok_probe_drift_missing_scenarios",
+		Help: "number of enabled probes without a scenario in redis, found by the last drift detection.",
+	})
+
+	//number of scenarios in redis which differ from their probe, found by the last drift detection
+	OutdatedScenarios = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "zerok_probe_drift_outdated_scenarios",
+		Help: "number of scenarios in redis which differ from their probe, found by the last drift detection.",
+	})
+
+	//total number of drifted scenarios which could not be repaired
+	DriftRepairsFailed = factory.NewCounter(prometheus.CounterOpts{
+		Name: "zerok_probe_drift_repairs_failed_total",
+		Help: "total number of drifted scenarios which could not be repaired.",
+	})
+
+	//duration of the reconciles of ZerokProbes and ClusterZerokProbes by kind and result, success or error
+	ReconcileDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "zerok_probe_reconcile_duration_seconds",
+		Help:    "duration of the reconciles of ZerokProbes and ClusterZerokProbes by kind and result.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"kind", "result"})
+
+	//duration of the operations on the scenario store by store type and operation
+	StoreOperationDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "zerok_scenario_store_operation_duration_seconds",
+		Help:    "duration of the operations on the scenario store by store type and operation.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"store", "operation"})
+
+	//total number of failed operations on the scenario store by store type and operation
+	StoreOperationErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "zerok_scenario_store_operation_errors_total",
+		Help: "total number of failed operations on the scenario store by store type and operation.",
+	}, []string{"store", "operation"})
+
+	//total number of failed translations of probes into scenarios by reason, the type of the offending field error
+	TranslationFailures = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "zerok_probe_translation_failures_total",
+		Help: "total number of failed translations of probes into scenarios by reason.",
+	}, []string{"reason"})
 )
+
+// RegisterStoredScenarios exports the number of scenarios currently in the store, count is called on every scrape.
+func RegisterStoredScenarios(count func() float64) {
+	factory.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "zerok_scenario_store_scenarios",
+		Help: "number of scenarios currently in the scenario store.",
+	}, count)
+}
@@ -0,0 +1,163 @@
+package resolver
+
+import (
+	"context"
+	operatorv1alpha1 "github.com/zerok-ai/zk-operator/api/v1alpha1"
+	"github.com/zerok-ai/zk-operator/internal/translator"
+	logger "github.com/zerok-ai/zk-utils-go/logs"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"strings"
+)
+
+var workloadResolverLog = "WorkloadResolver"
+
+// Env of the containers the OTel service name of a pod is read from, OTEL_SERVICE_NAME takes precedence.
+const (
+	otelServiceNameEnv        = "OTEL_SERVICE_NAME"
+	otelResourceAttributesEnv = "OTEL_RESOURCE_ATTRIBUTES"
+	serviceNameAttribute      = "service.name"
+)
+
+// HasWorkloadSelectors tells whether any workload of the spec selects its pods.
+func HasWorkloadSelectors(spec *operatorv1alpha1.ZerokProbeSpec) bool {
+	for _, workload := range spec.Workloads {
+		if workload.Selector != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveWorkloadServices reads the pods and deployments selected by the workloads of the probe and returns the OTel
+// service names they report, by workload key. A selector which selects nothing resolves to no services.
+func ResolveWorkloadServices(ctx context.Context, reader client.Reader, zerokProbe operatorv1alpha1.Probe) (translator.WorkloadServices, error) {
+	workloadServices := translator.WorkloadServices{}
+	for key, workload := range zerokProbe.GetSpec().Workloads {
+		if workload.Selector == nil {
+			continue
+		}
+		services, err := resolveSelector(ctx, reader, workload.Selector, selectorNamespace(workload.Selector, zerokProbe.GetNamespace()))
+		if err != nil {
+			logger.Error(workloadResolverLog, "Error while resolving the selector of workload ", key, " of probe ", zerokProbe.GetName(), " ", err)
+			return nil, err
+		}
+		workloadServices[key] = services
+	}
+	return workloadServices, nil
+}
+
+// SelectsObject tells whether a pod or deployment is selected by a workload of the spec, so that the probe has to be
+// translated again when the object changes.
+func SelectsObject(spec *operatorv1alpha1.ZerokProbeSpec, probeNamespace string, object client.Object) bool {
+	for _, workload := range spec.Workloads {
+		selector := workload.Selector
+		if selector == nil || selectorNamespace(selector, probeNamespace) != object.GetNamespace() {
+			continue
+		}
+		switch object.(type) {
+		case *corev1.Pod:
+			if selector.PodSelector == nil {
+				continue
+			}
+			labelSelector, err := metav1.LabelSelectorAsSelector(selector.PodSelector)
+			if err == nil && labelSelector.Matches(labels.Set(object.GetLabels())) {
+				return true
+			}
+		case *appsv1.Deployment:
+			if selector.Deployment == object.GetName() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func resolveSelector(ctx context.Context, reader client.Reader, selector *operatorv1alpha1.WorkloadSelector, namespace string) ([]string, error) {
+	if namespace == "" {
+		return nil, nil
+	}
+
+	services := map[string]bool{}
+	if selector.PodSelector != nil {
+		labelSelector, err := metav1.LabelSelectorAsSelector(selector.PodSelector)
+		if err != nil {
+			// an invalid selector is reported by the validation of the spec
+			return nil, nil
+		}
+		pods := &corev1.PodList{}
+		if err := reader.List(ctx, pods, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: labelSelector}); err != nil {
+			return nil, err
+		}
+		for _, pod := range pods.Items {
+			if !pod.GetDeletionTimestamp().IsZero() {
+				continue
+			}
+			addServiceNames(services, pod.Spec.Containers)
+		}
+	} else if selector.Deployment != "" {
+		deployment := &appsv1.Deployment{}
+		err := reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: selector.Deployment}, deployment)
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		addServiceNames(services, deployment.Spec.Template.Spec.Containers)
+	}
+
+	serviceNames := make([]string, 0, len(services))
+	for service := range services {
+		serviceNames = append(serviceNames, service)
+	}
+	sort.Strings(serviceNames)
+	return serviceNames, nil
+}
+
+// addServiceNames adds the OTel service name of every container which sets one in its env.
+func addServiceNames(services map[string]bool, containers []corev1.Container) {
+	for _, container := range containers {
+		if serviceName := containerServiceName(container); serviceName != "" {
+			services[serviceName] = true
+		}
+	}
+}
+
+func containerServiceName(container corev1.Container) string {
+	resourceServiceName := ""
+	for _, env := range container.Env {
+		switch env.Name {
+		case otelServiceNameEnv:
+			if env.Value != "" {
+				return env.Value
+			}
+		case otelResourceAttributesEnv:
+			resourceServiceName = serviceNameFromResourceAttributes(env.Value)
+		}
+	}
+	return resourceServiceName
+}
+
+// serviceNameFromResourceAttributes reads service.name from resource attributes in the key1=value1,key2=value2 form.
+func serviceNameFromResourceAttributes(resourceAttributes string) string {
+	for _, attribute := range strings.Split(resourceAttributes, ",") {
+		key, value, found := strings.Cut(attribute, "=")
+		if found && strings.TrimSpace(key) == serviceNameAttribute {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+func selectorNamespace(selector *operatorv1alpha1.WorkloadSelector, probeNamespace string) string {
+	if selector.Namespace != "" {
+		return selector.Namespace
+	}
+	return probeNamespace
+}
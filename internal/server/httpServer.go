@@ -6,11 +6,15 @@ import (
 	"github.com/zerok-ai/zk-operator/internal/config"
 	"github.com/zerok-ai/zk-operator/internal/handler"
 	logger "github.com/zerok-ai/zk-utils-go/logs"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var LOG_TAG_HTTP = "HttpServer"
 
-func StartHttpServer(app *iris.Application, config iris.Configurator, zkConfig config.ZkOperatorConfig, modules []internal.ZkOperatorModule) {
+// ProbeApiPath is the prefix of the probe api, which is not meant to be called from a browser.
+const ProbeApiPath = "/v1/probes"
+
+func StartHttpServer(app *iris.Application, config iris.Configurator, zkConfig config.ZkOperatorConfig, modules []internal.ZkOperatorModule, reader client.Reader, crdProbeHandler *handler.ZkCRDProbeHandler) {
 
 	httpServerConfig := zkConfig.Http
 	logger.Debug(LOG_TAG_HTTP, zkConfig.ClusterContext.Path)
@@ -20,7 +24,17 @@ func StartHttpServer(app *iris.Application, config iris.Configurator, zkConfig c
 
 	app.Get("/healthz", healthCheckHandler.Handler)
 
-	err := app.Run(iris.Addr(":"+httpServerConfig.Port), config)
+	probeApiHandler := handler.ProbeApiHandler{}
+	probeApiHandler.Init(reader, crdProbeHandler)
+
+	probesApi := app.Party(ProbeApiPath)
+	probesApi.Get("/", probeApiHandler.ListProbes)
+	probesApi.Get("/{id}", probeApiHandler.GetProbe)
+	probesApi.Get("/{id}/scenario", probeApiHandler.GetProbeScenario)
+	app.Post(ProbeApiPath+":translate", probeApiHandler.TranslateProbe)
+	app.Post(ProbeApiPath+":evaluate", probeApiHandler.EvaluateProbe)
+
+	err := app.Run(iris.Addr(":"+httpServerConfig.Port), config, iris.WithoutServerError(iris.ErrServerClosed))
 	if err != nil {
 		logger.Error(LOG_TAG_HTTP, "Error while starting http server ", err)
 		return
@@ -0,0 +1,187 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/zerok-ai/zk-operator/internal/config"
+	"github.com/zerok-ai/zk-utils-go/scenario/model"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
+)
+
+const configMapRequestTimeout = 10 * time.Second
+
+// ConfigMapScenarioStore keeps the scenarios as json in the data of a single ConfigMap, for clusters which do not
+// run redis. The size of a ConfigMap is limited to 1MiB, which bounds the number of probes it can hold.
+type ConfigMapScenarioStore struct {
+	client    client.Client
+	name      string
+	namespace string
+}
+
+var _ ScenarioStore = &ConfigMapScenarioStore{}
+
+func NewConfigMapScenarioStore(cfg config.ConfigMapStoreConfig) (*ConfigMapScenarioStore, error) {
+	restConfig, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	// the ConfigMap is read from the api server directly, so that it is not cached and watched cluster wide
+	kubeClient, err := client.New(restConfig, client.Options{})
+	if err != nil {
+		return nil, err
+	}
+	return &ConfigMapScenarioStore{client: kubeClient, name: cfg.Name, namespace: cfg.Namespace}, nil
+}
+
+func (s *ConfigMapScenarioStore) Set(id string, scenario model.Scenario) error {
+	value, err := json.Marshal(scenario)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), configMapRequestTimeout)
+	defer cancel()
+
+	latest := false
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap, err := s.getConfigMap(ctx)
+		if apierrors.IsNotFound(err) {
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: s.name, Namespace: s.namespace},
+				Data:       map[string]string{id: string(value)},
+			}
+			return s.client.Create(ctx, configMap)
+		}
+		if err != nil {
+			return err
+		}
+
+		if storedScenario, err := toScenario(configMap.Data[id]); err == nil && storedScenario != nil && storedScenario.Equals(scenario) {
+			latest = true
+			return nil
+		}
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[id] = string(value)
+		return s.client.Update(ctx, configMap)
+	})
+	if err != nil {
+		return err
+	}
+	if latest {
+		return ErrLatest
+	}
+	return nil
+}
+
+func (s *ConfigMapScenarioStore) Get(id string) (*model.Scenario, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), configMapRequestTimeout)
+	defer cancel()
+
+	configMap, err := s.getConfigMap(ctx)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toScenario(configMap.Data[id])
+}
+
+func (s *ConfigMapScenarioStore) Delete(id string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), configMapRequestTimeout)
+	defer cancel()
+
+	deleted := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		deleted = false
+		configMap, err := s.getConfigMap(ctx)
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, ok := configMap.Data[id]; !ok {
+			return nil
+		}
+		delete(configMap.Data, id)
+		deleted = true
+		return s.client.Update(ctx, configMap)
+	})
+	return deleted && err == nil, err
+}
+
+func (s *ConfigMapScenarioStore) List() (map[string]model.Scenario, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), configMapRequestTimeout)
+	defer cancel()
+
+	scenarios := map[string]model.Scenario{}
+	configMap, err := s.getConfigMap(ctx)
+	if apierrors.IsNotFound(err) {
+		return scenarios, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for id, value := range configMap.Data {
+		scenario, err := toScenario(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scenario %s in configmap %s/%s: %v", id, s.namespace, s.name, err)
+		}
+		scenarios[id] = *scenario
+	}
+	return scenarios, nil
+}
+
+func (s *ConfigMapScenarioStore) Version(id string) (string, error) {
+	scenario, err := s.Get(id)
+	if err != nil || scenario == nil {
+		return "", err
+	}
+	return scenario.Version, nil
+}
+
+// Ping writes the ConfigMap as a server side dry run, which checks both the connection to the api server and the
+// permission to write it.
+func (s *ConfigMapScenarioStore) Ping(ctx context.Context) error {
+	configMap, err := s.getConfigMap(ctx)
+	if apierrors.IsNotFound(err) {
+		configMap = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: s.name, Namespace: s.namespace}}
+		return s.client.Create(ctx, configMap, client.DryRunAll)
+	}
+	if err != nil {
+		return err
+	}
+	return s.client.Update(ctx, configMap, client.DryRunAll)
+}
+
+func (s *ConfigMapScenarioStore) Close() error {
+	return nil
+}
+
+func (s *ConfigMapScenarioStore) getConfigMap(ctx context.Context) (*corev1.ConfigMap, error) {
+	configMap := &corev1.ConfigMap{}
+	err := s.client.Get(ctx, client.ObjectKey{Namespace: s.namespace, Name: s.name}, configMap)
+	return configMap, err
+}
+
+// toScenario returns nil for an empty value, i.e. for an id which is not in the ConfigMap.
+func toScenario(value string) (*model.Scenario, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var scenario model.Scenario
+	if err := json.Unmarshal([]byte(value), &scenario); err != nil {
+		return nil, err
+	}
+	return &scenario, nil
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"errors"
+	promMetrics "github.com/zerok-ai/zk-operator/internal/metrics"
+	"github.com/zerok-ai/zk-utils-go/scenario/model"
+	"time"
+)
+
+// InstrumentedScenarioStore exports the latency and the errors of every operation on the wrapped store.
+type InstrumentedScenarioStore struct {
+	store     ScenarioStore
+	storeType string
+}
+
+var _ ScenarioStore = &InstrumentedScenarioStore{}
+
+func NewInstrumentedScenarioStore(store ScenarioStore, storeType string) *InstrumentedScenarioStore {
+	return &InstrumentedScenarioStore{store: store, storeType: storeType}
+}
+
+func (s *InstrumentedScenarioStore) Set(id string, scenario model.Scenario) error {
+	start := time.Now()
+	err := s.store.Set(id, scenario)
+	// an unchanged scenario is not a failure of the store
+	if errors.Is(err, ErrLatest) {
+		s.observe("set", start, nil)
+	} else {
+		s.observe("set", start, err)
+	}
+	return err
+}
+
+func (s *InstrumentedScenarioStore) Get(id string) (*model.Scenario, error) {
+	start := time.Now()
+	scenario, err := s.store.Get(id)
+	s.observe("get", start, err)
+	return scenario, err
+}
+
+func (s *InstrumentedScenarioStore) Delete(id string) (bool, error) {
+	start := time.Now()
+	deleted, err := s.store.Delete(id)
+	s.observe("delete", start, err)
+	return deleted, err
+}
+
+func (s *InstrumentedScenarioStore) List() (map[string]model.Scenario, error) {
+	start := time.Now()
+	scenarios, err := s.store.List()
+	s.observe("list", start, err)
+	return scenarios, err
+}
+
+func (s *InstrumentedScenarioStore) Version(id string) (string, error) {
+	start := time.Now()
+	version, err := s.store.Version(id)
+	s.observe("version", start, err)
+	return version, err
+}
+
+func (s *InstrumentedScenarioStore) Ping(ctx context.Context) error {
+	start := time.Now()
+	err := s.store.Ping(ctx)
+	s.observe("ping", start, err)
+	return err
+}
+
+func (s *InstrumentedScenarioStore) Close() error {
+	return s.store.Close()
+}
+
+func (s *InstrumentedScenarioStore) observe(operation string, start time.Time, err error) {
+	promMetrics.StoreOperationDuration.WithLabelValues(s.storeType, operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		promMetrics.StoreOperationErrors.WithLabelValues(s.storeType, operation).Inc()
+	}
+}
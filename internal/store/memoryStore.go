@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"github.com/zerok-ai/zk-utils-go/scenario/model"
+	"sync"
+)
+
+// MemoryScenarioStore keeps the scenarios in memory only. It is meant for tests and local runs, the scenarios are
+// neither shared with the collectors nor kept across restarts.
+type MemoryScenarioStore struct {
+	scenarios map[string]model.Scenario
+	mutex     sync.RWMutex
+}
+
+var _ ScenarioStore = &MemoryScenarioStore{}
+
+func NewMemoryScenarioStore() *MemoryScenarioStore {
+	return &MemoryScenarioStore{scenarios: map[string]model.Scenario{}}
+}
+
+func (s *MemoryScenarioStore) Set(id string, scenario model.Scenario) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if storedScenario, ok := s.scenarios[id]; ok && storedScenario.Equals(scenario) {
+		return ErrLatest
+	}
+	s.scenarios[id] = scenario
+	return nil
+}
+
+func (s *MemoryScenarioStore) Get(id string) (*model.Scenario, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	scenario, ok := s.scenarios[id]
+	if !ok {
+		return nil, nil
+	}
+	return &scenario, nil
+}
+
+func (s *MemoryScenarioStore) Delete(id string) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, ok := s.scenarios[id]
+	delete(s.scenarios, id)
+	return ok, nil
+}
+
+func (s *MemoryScenarioStore) List() (map[string]model.Scenario, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	scenarios := make(map[string]model.Scenario, len(s.scenarios))
+	for id, scenario := range s.scenarios {
+		scenarios[id] = scenario
+	}
+	return scenarios, nil
+}
+
+func (s *MemoryScenarioStore) Version(id string) (string, error) {
+	scenario, err := s.Get(id)
+	if err != nil || scenario == nil {
+		return "", err
+	}
+	return scenario.Version, nil
+}
+
+func (s *MemoryScenarioStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *MemoryScenarioStore) Close() error {
+	return nil
+}
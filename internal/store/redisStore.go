@@ -0,0 +1,186 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"github.com/zerok-ai/zk-operator/internal/common"
+	"github.com/zerok-ai/zk-operator/internal/config"
+	"github.com/zerok-ai/zk-utils-go/scenario/model"
+	zkredis "github.com/zerok-ai/zk-utils-go/storage/redis"
+	dbNames "github.com/zerok-ai/zk-utils-go/storage/redis/clientDBNames"
+	redisConfig "github.com/zerok-ai/zk-utils-go/storage/redis/config"
+	"sync"
+	"time"
+)
+
+// healthCheckKey is written by Ping. It has no entry in the version hash of the versioned store, so it is never
+// read as a scenario.
+const (
+	healthCheckKey = "zk_operator_health_check"
+	healthCheckTTL = 30 * time.Second
+)
+
+// The scenarios db is shared with the rest of the stack, so the ids of the scenarios written by the operator are kept
+// in the ownedScenariosKey set, and only those are listed. Like the health check key, the set has no entry in the
+// version hash and is never read as a scenario.
+const (
+	ownedScenariosKey = "zk_operator_scenarios"
+	versionHashKey    = "zk_value_version"
+)
+
+// RedisScenarioStore keeps the scenarios in the versioned scenarios db of redis, which the collectors read from.
+type RedisScenarioStore struct {
+	versionedStore *zkredis.VersionedStore[model.Scenario]
+	redisClient    *redis.Client
+	// mutex guards the local cache of the versioned store, which is not safe to read while it is written
+	mutex sync.Mutex
+}
+
+var _ ScenarioStore = &RedisScenarioStore{}
+
+func NewRedisScenarioStore(cfg config.ZkOperatorConfig) (*RedisScenarioStore, error) {
+	versionedStore, err := zkredis.GetVersionedStore[model.Scenario](&cfg.Redis, dbNames.ScenariosDBName, common.RedisSyncInterval)
+	if err != nil {
+		return nil, err
+	}
+	return &RedisScenarioStore{
+		versionedStore: versionedStore,
+		redisClient:    redisConfig.GetRedisConnection(dbNames.ScenariosDBName, cfg.Redis),
+	}, nil
+}
+
+func (s *RedisScenarioStore) Set(id string, scenario model.Scenario) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ctx := context.Background()
+	// the id is owned before the scenario is written, so that a scenario of the operator is never left out of List
+	if err := s.redisClient.SAdd(ctx, ownedScenariosKey, id).Err(); err != nil {
+		return err
+	}
+	err := s.versionedStore.SetValue(id, scenario)
+	if !errors.Is(err, zkredis.LATEST) {
+		return err
+	}
+
+	// the versioned store compares with its local cache, which lags redis by up to the sync interval
+	storedScenario, err := s.getFromRedis(ctx, id)
+	if err != nil {
+		return err
+	}
+	if storedScenario != nil && storedScenario.Equals(scenario) {
+		return ErrLatest
+	}
+	return s.setInRedis(ctx, id, scenario)
+}
+
+func (s *RedisScenarioStore) Get(id string) (*model.Scenario, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	scenario, ok := s.versionedStore.GetAllValues()[id]
+	if !ok || scenario == nil {
+		return nil, nil
+	}
+	storedScenario := *scenario
+	return &storedScenario, nil
+}
+
+func (s *RedisScenarioStore) Delete(id string) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ctx := context.Background()
+	// the versioned store does not tell whether it deleted a key, so redis is asked before
+	existing, err := s.redisClient.Exists(ctx, id).Result()
+	if err != nil {
+		return false, err
+	}
+	if err := s.versionedStore.Delete(id); err != nil {
+		return false, err
+	}
+	return existing > 0, s.redisClient.SRem(ctx, ownedScenariosKey, id).Err()
+}
+
+// List reads the scenarios owned by the operator straight from redis instead of the local cache of the versioned
+// store, so that the drift detection compares the probes with what the collectors read.
+func (s *RedisScenarioStore) List() (map[string]model.Scenario, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ctx := context.Background()
+	scenarios := map[string]model.Scenario{}
+	ids, err := s.redisClient.SMembers(ctx, ownedScenariosKey).Result()
+	if err != nil || len(ids) == 0 {
+		return scenarios, err
+	}
+	values, err := s.redisClient.MGet(ctx, ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range values {
+		// an owned id without a value was deleted by someone else, there is nothing to list for it
+		stringValue, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var scenario model.Scenario
+		if err := json.Unmarshal([]byte(stringValue), &scenario); err != nil {
+			return nil, fmt.Errorf("invalid scenario %s in redis: %v", ids[i], err)
+		}
+		scenarios[ids[i]] = scenario
+	}
+	return scenarios, nil
+}
+
+func (s *RedisScenarioStore) Version(id string) (string, error) {
+	scenario, err := s.Get(id)
+	if err != nil || scenario == nil {
+		return "", err
+	}
+	return scenario.Version, nil
+}
+
+// Ping writes the health check key, which expires on its own, so a read only replica or a full db fails the check.
+// The caller bounds the write with the timeout of ctx, store.pingTimeout for the readiness check.
+func (s *RedisScenarioStore) Ping(ctx context.Context) error {
+	return s.redisClient.Set(ctx, healthCheckKey, time.Now().Unix(), healthCheckTTL).Err()
+}
+
+// getFromRedis reads the scenario stored under id from redis, nil if there is none.
+func (s *RedisScenarioStore) getFromRedis(ctx context.Context, id string) (*model.Scenario, error) {
+	value, err := s.redisClient.Get(ctx, id).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var scenario model.Scenario
+	if err := json.Unmarshal([]byte(value), &scenario); err != nil {
+		return nil, err
+	}
+	return &scenario, nil
+}
+
+// setInRedis writes the scenario and bumps its version the same way as the versioned store does, for when its
+// local cache already holds the scenario but redis does not.
+func (s *RedisScenarioStore) setInRedis(ctx context.Context, id string, scenario model.Scenario) error {
+	value, err := json.Marshal(scenario)
+	if err != nil {
+		return err
+	}
+	tx := s.redisClient.TxPipeline()
+	tx.Set(ctx, id, string(value), 0)
+	tx.HIncrBy(ctx, versionHashKey, id, 1)
+	_, err = tx.Exec(ctx)
+	return err
+}
+
+func (s *RedisScenarioStore) Close() error {
+	s.versionedStore.Close()
+	return s.redisClient.Close()
+}
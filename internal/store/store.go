@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/zerok-ai/zk-operator/internal/config"
+	"github.com/zerok-ai/zk-utils-go/scenario/model"
+)
+
+const (
+	StoreTypeRedis     = "redis"
+	StoreTypeMemory    = "memory"
+	StoreTypeConfigMap = "configmap"
+)
+
+// ErrLatest is returned by Set when the store already holds the same scenario.
+var ErrLatest = errors.New("latest value is already present in the store")
+
+// ScenarioStore is where the scenarios translated from the probes are written for the collectors to pick up.
+type ScenarioStore interface {
+	// Set stores the scenario under id, ErrLatest is returned when the stored scenario is already equal.
+	Set(id string, scenario model.Scenario) error
+	// Get returns the scenario stored under id, or nil if there is none.
+	Get(id string) (*model.Scenario, error)
+	// Delete removes the scenario stored under id and reports whether there was one, deleting a missing id is not an
+	// error.
+	Delete(id string) (bool, error)
+	// List returns a copy of the scenarios written by the operator by id. It reads the store itself, not a cache, and
+	// leaves out the scenarios written by other components to a shared store.
+	List() (map[string]model.Scenario, error)
+	// Version returns the version of the scenario stored under id, or an empty string if there is none.
+	Version(id string) (string, error)
+	// Ping checks that the store is reachable and writable, without changing any scenario.
+	Ping(ctx context.Context) error
+	// Close releases the connections of the store.
+	Close() error
+}
+
+// NewScenarioStore creates the store of the type configured in cfg.Store, redis is used when no type is set. The
+// store is instrumented with metrics.
+func NewScenarioStore(cfg config.ZkOperatorConfig) (ScenarioStore, error) {
+	storeType := cfg.Store.Type
+	if storeType == "" {
+		storeType = StoreTypeRedis
+	}
+
+	var store ScenarioStore
+	var err error
+	switch storeType {
+	case StoreTypeRedis:
+		store, err = NewRedisScenarioStore(cfg)
+	case StoreTypeMemory:
+		store = NewMemoryScenarioStore()
+	case StoreTypeConfigMap:
+		store, err = NewConfigMapScenarioStore(cfg.Store.ConfigMap)
+	default:
+		return nil, fmt.Errorf("unknown scenario store type %q", cfg.Store.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewInstrumentedScenarioStore(store, storeType), nil
+}
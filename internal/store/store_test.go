@@ -0,0 +1,138 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/zerok-ai/zk-utils-go/scenario/model"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newScenario returns a scenario with an empty map of workloads, model.Scenario.Equals does not handle nil workloads.
+func newScenario(id string, version string) model.Scenario {
+	workloads := map[string]model.Workload{}
+	return model.Scenario{Id: id, Title: "errors of " + id, Version: version, Enabled: true, Workloads: &workloads}
+}
+
+func newConfigMapStore(t *testing.T) *ConfigMapScenarioStore {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return &ConfigMapScenarioStore{client: fake.NewClientBuilder().WithScheme(scheme).Build(), name: "zk-scenarios", namespace: "zk-client"}
+}
+
+// TestScenarioStore checks the contract of ScenarioStore on the stores which do not need a running server.
+func TestScenarioStore(t *testing.T) {
+	stores := map[string]func(t *testing.T) ScenarioStore{
+		StoreTypeMemory:    func(t *testing.T) ScenarioStore { return NewMemoryScenarioStore() },
+		StoreTypeConfigMap: func(t *testing.T) ScenarioStore { return newConfigMapStore(t) },
+	}
+	tests := []struct {
+		name string
+		// run makes its calls on an empty store
+		run func(t *testing.T, store ScenarioStore)
+	}{
+		{name: "get missing scenario", run: func(t *testing.T, store ScenarioStore) {
+			scenario, err := store.Get("orders")
+			if err != nil || scenario != nil {
+				t.Errorf("expected no scenario, got %v and %v", scenario, err)
+			}
+			version, err := store.Version("orders")
+			if err != nil || version != "" {
+				t.Errorf("expected no version, got %q and %v", version, err)
+			}
+		}},
+		{name: "set and get", run: func(t *testing.T, store ScenarioStore) {
+			if err := store.Set("orders", newScenario("orders", "1-abcdef12")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			scenario, err := store.Get("orders")
+			if err != nil || scenario == nil || !scenario.Equals(newScenario("orders", "1-abcdef12")) {
+				t.Errorf("expected the stored scenario, got %v and %v", scenario, err)
+			}
+			version, err := store.Version("orders")
+			if err != nil || version != "1-abcdef12" {
+				t.Errorf("expected version 1-abcdef12, got %q and %v", version, err)
+			}
+		}},
+		{name: "set the same scenario again", run: func(t *testing.T, store ScenarioStore) {
+			if err := store.Set("orders", newScenario("orders", "1-abcdef12")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := store.Set("orders", newScenario("orders", "1-abcdef12")); !errors.Is(err, ErrLatest) {
+				t.Errorf("expected ErrLatest, got %v", err)
+			}
+			if err := store.Set("orders", newScenario("orders", "2-abcdef12")); err != nil {
+				t.Errorf("unexpected error on a new version: %v", err)
+			}
+		}},
+		{name: "list", run: func(t *testing.T, store ScenarioStore) {
+			for _, id := range []string{"orders", "cart"} {
+				if err := store.Set(id, newScenario(id, "1-abcdef12")); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			scenarios, err := store.List()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ids := map[string]string{}
+			for id, scenario := range scenarios {
+				ids[id] = scenario.Title
+			}
+			expected := map[string]string{"orders": "errors of orders", "cart": "errors of cart"}
+			if !reflect.DeepEqual(ids, expected) {
+				t.Errorf("expected scenarios %v, got %v", expected, ids)
+			}
+		}},
+		{name: "delete", run: func(t *testing.T, store ScenarioStore) {
+			if deleted, err := store.Delete("orders"); err != nil || deleted {
+				t.Errorf("expected nothing to be deleted for a missing scenario, got %t and %v", deleted, err)
+			}
+			if err := store.Set("orders", newScenario("orders", "1-abcdef12")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if deleted, err := store.Delete("orders"); err != nil || !deleted {
+				t.Fatalf("expected the scenario to be deleted, got %t and %v", deleted, err)
+			}
+			if scenario, err := store.Get("orders"); err != nil || scenario != nil {
+				t.Errorf("expected the scenario to be deleted, got %v and %v", scenario, err)
+			}
+		}},
+		{name: "ping", run: func(t *testing.T, store ScenarioStore) {
+			if err := store.Ping(context.Background()); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}},
+	}
+	for storeType, newStore := range stores {
+		for _, tt := range tests {
+			t.Run(storeType+"/"+tt.name, func(t *testing.T) {
+				tt.run(t, newStore(t))
+			})
+		}
+	}
+}
+
+func TestConfigMapStoreListRejectsInvalidScenarios(t *testing.T) {
+	store := newConfigMapStore(t)
+	if err := store.Set("orders", newScenario("orders", "1-abcdef12")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	configMap, err := store.getConfigMap(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	configMap.Data["cart"] = "{"
+	if err := store.client.Update(context.Background(), configMap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := store.List(); err == nil {
+		t.Errorf("expected an error for the invalid scenario")
+	}
+}
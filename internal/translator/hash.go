@@ -0,0 +1,24 @@
+package translator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/zerok-ai/zk-utils-go/scenario/model"
+)
+
+// scenarioVersionHashLength is the number of characters of the hash kept in the version of a scenario.
+const scenarioVersionHashLength = 8
+
+// ScenarioHash is the hash of the content of a scenario. The version is left out, so the hash only changes when
+// the scenario read by the collectors would change.
+func ScenarioHash(scenario model.Scenario) string {
+	scenario.Version = ""
+	// the maps in the scenario are marshalled with sorted keys, which makes the json stable
+	content, err := json.Marshal(scenario)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
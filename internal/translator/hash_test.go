@@ -0,0 +1,80 @@
+package translator_test
+
+import (
+	"strings"
+	"testing"
+
+	operatorv1alpha1 "github.com/zerok-ai/zk-operator/api/v1alpha1"
+	"github.com/zerok-ai/zk-operator/internal/translator"
+	"github.com/zerok-ai/zk-utils-go/scenario/model"
+)
+
+const versionSpec = `
+title: errors
+enabled: true
+workloads:
+  OTEL/orders: {rule: ` + specRule + `}
+`
+
+func TestScenarioVersion(t *testing.T) {
+	translate := func(probe *operatorv1alpha1.ZerokProbe) model.Scenario {
+		scenario, err := translator.TranslateZerokProbe(probe)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return scenario
+	}
+	base := translate(newSpecProbe(t, versionSpec))
+
+	tests := []struct {
+		name string
+		// change is made to the probe before it is translated
+		change func(probe *operatorv1alpha1.ZerokProbe)
+		// sameHash tells whether the hash in the version is the one of the unchanged probe
+		sameHash   bool
+		generation string
+	}{
+		{name: "same spec", change: func(probe *operatorv1alpha1.ZerokProbe) {}, sameHash: true, generation: "1"},
+		{name: "new generation of the same spec", change: func(probe *operatorv1alpha1.ZerokProbe) {
+			probe.Generation = 2
+		}, sameHash: true, generation: "2"},
+		{name: "rate limit written another way", change: func(probe *operatorv1alpha1.ZerokProbe) {
+			probe.Spec.RateLimit = []operatorv1alpha1.RateLimit{{BucketMaxSize: 5, BucketRefillSize: 5, TickDuration: "60s"}}
+		}, sameHash: true, generation: "1"},
+		{name: "changed rule", change: func(probe *operatorv1alpha1.ZerokProbe) {
+			condition := model.OR
+			probe.Spec.Workloads["OTEL/orders"].Rule.RuleGroup.Condition = &condition
+		}, sameHash: false, generation: "1"},
+		{name: "changed title", change: func(probe *operatorv1alpha1.ZerokProbe) {
+			probe.Spec.Title = "orders errors"
+		}, sameHash: false, generation: "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe := newSpecProbe(t, versionSpec)
+			tt.change(probe)
+			scenario := translate(probe)
+
+			generation, hash, ok := strings.Cut(scenario.Version, "-")
+			if !ok || generation != tt.generation || len(hash) != 8 {
+				t.Fatalf("expected a version of generation %s with an 8 character hash, got %q", tt.generation, scenario.Version)
+			}
+			if !strings.HasPrefix(translator.ScenarioHash(scenario), hash) {
+				t.Errorf("expected the version %q to carry the hash of the scenario", scenario.Version)
+			}
+			_, baseHash, _ := strings.Cut(base.Version, "-")
+			if (hash == baseHash) != tt.sameHash {
+				t.Errorf("expected the same hash %v, got %q and %q", tt.sameHash, hash, baseHash)
+			}
+		})
+	}
+}
+
+func TestScenarioHashLeavesOutTheVersion(t *testing.T) {
+	scenario := model.Scenario{Id: "probe-uid", Title: "errors", Version: "1-abcdef12"}
+	hash := translator.ScenarioHash(scenario)
+	scenario.Version = "2-12abcdef"
+	if translator.ScenarioHash(scenario) != hash {
+		t.Errorf("expected the hash not to change with the version")
+	}
+}
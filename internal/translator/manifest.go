@@ -0,0 +1,75 @@
+package translator
+
+import (
+	"errors"
+	"fmt"
+	operatorv1alpha1 "github.com/zerok-ai/zk-operator/api/v1alpha1"
+	"github.com/zerok-ai/zk-utils-go/scenario/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// TranslationResult is the outcome of a dry run translation of a ZerokProbe or ClusterZerokProbe manifest.
+type TranslationResult struct {
+	Scenario *model.Scenario `json:"scenario,omitempty"`
+	Errors   []string        `json:"errors,omitempty"`
+}
+
+// TranslateFunc translates a probe read from a manifest into a scenario.
+type TranslateFunc func(zerokProbe operatorv1alpha1.Probe) (model.Scenario, error)
+
+// TranslateManifest translates a ZerokProbe or ClusterZerokProbe manifest in yaml or json into the scenario that
+// would be stored in redis, without applying it to the cluster. It works without a cluster, so a probe using a
+// template or workload selectors is reported as an error, see TranslateManifestWith for the translation of the
+// operator.
+func TranslateManifest(manifest []byte) TranslationResult {
+	return TranslateManifestWith(manifest, TranslateZerokProbe)
+}
+
+// TranslateManifestWith translates a ZerokProbe or ClusterZerokProbe manifest in yaml or json with translate.
+// A manifest without a kind is read as a ZerokProbe. Unknown fields in the manifest are reported as errors.
+func TranslateManifestWith(manifest []byte, translate TranslateFunc) TranslationResult {
+	zerokProbe, err := ParseManifest(manifest)
+	if err != nil {
+		return TranslationResult{Errors: []string{err.Error()}}
+	}
+
+	scenario, err := translate(zerokProbe)
+	if err == nil {
+		return TranslationResult{Scenario: &scenario}
+	}
+
+	var translationErr *TranslationError
+	if !errors.As(err, &translationErr) {
+		return TranslationResult{Errors: []string{err.Error()}}
+	}
+	result := TranslationResult{}
+	for _, fieldErr := range translationErr.Errs {
+		result.Errors = append(result.Errors, fieldErr.Error())
+	}
+	return result
+}
+
+// ParseManifest reads a ZerokProbe or ClusterZerokProbe manifest in yaml or json. A manifest without a kind is read
+// as a ZerokProbe.
+func ParseManifest(manifest []byte) (operatorv1alpha1.Probe, error) {
+	typeMeta := metav1.TypeMeta{}
+	if err := yaml.Unmarshal(manifest, &typeMeta); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %s", err.Error())
+	}
+
+	var zerokProbe operatorv1alpha1.Probe
+	switch typeMeta.Kind {
+	case "", operatorv1alpha1.ZerokProbeKind:
+		zerokProbe = &operatorv1alpha1.ZerokProbe{}
+	case operatorv1alpha1.ClusterZerokProbeKind:
+		zerokProbe = &operatorv1alpha1.ClusterZerokProbe{}
+	default:
+		return nil, fmt.Errorf("invalid manifest: kind %s is neither %s nor %s",
+			typeMeta.Kind, operatorv1alpha1.ZerokProbeKind, operatorv1alpha1.ClusterZerokProbeKind)
+	}
+	if err := yaml.UnmarshalStrict(manifest, zerokProbe); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %s", err.Error())
+	}
+	return zerokProbe, nil
+}
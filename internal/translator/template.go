@@ -0,0 +1,107 @@
+package translator
+
+import (
+	"encoding/json"
+	"fmt"
+	operatorv1alpha1 "github.com/zerok-ai/zk-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"regexp"
+	"slices"
+	"sort"
+)
+
+// templateParameterPattern matches the references to the parameters in a template, e.g. ${service}.
+var templateParameterPattern = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// RenderZerokProbe returns a copy of the probe with the spec rendered from the template it refers to, the probe is
+// returned as it is when it does not refer to a template. A *TranslationError is returned when the parameters set
+// on the probe do not match the ones declared by the template.
+func RenderZerokProbe(zerokProbe operatorv1alpha1.Probe, template *operatorv1alpha1.ZerokProbeTemplate) (operatorv1alpha1.Probe, error) {
+	templateRef := zerokProbe.GetSpec().Template
+	if templateRef == nil {
+		return zerokProbe, nil
+	}
+
+	templatePath := field.NewPath("spec", "template")
+	values, allErrs := getTemplateParameterValues(templateRef, template, templatePath.Child("parameters"))
+	if len(allErrs) > 0 {
+		return nil, &TranslationError{Errs: allErrs}
+	}
+	renderedSpec, allErrs := renderTemplateSpec(template, values, templatePath.Child("name"))
+	if len(allErrs) > 0 {
+		return nil, &TranslationError{Errs: allErrs}
+	}
+
+	renderedProbe := zerokProbe.DeepCopyObject().(operatorv1alpha1.Probe)
+	spec := renderedProbe.GetSpec()
+	spec.Template = nil
+	spec.WorkloadScope = renderedSpec.WorkloadScope
+	spec.Workloads = renderedSpec.Workloads
+	spec.Filter = renderedSpec.Filter
+	spec.GroupBy = renderedSpec.GroupBy
+	spec.RateLimit = renderedSpec.RateLimit
+	return renderedProbe, nil
+}
+
+// getTemplateParameterValues returns the value of every parameter declared by the template, the values set on the
+// probe take precedence over the defaults of the template.
+func getTemplateParameterValues(templateRef *operatorv1alpha1.ZerokProbeTemplateRef, template *operatorv1alpha1.ZerokProbeTemplate, fldPath *field.Path) (map[string]string, field.ErrorList) {
+	allErrs := field.ErrorList{}
+	values := map[string]string{}
+	declaredNames := make([]string, 0, len(template.Spec.Parameters))
+	for _, parameter := range template.Spec.Parameters {
+		declaredNames = append(declaredNames, parameter.Name)
+		if value, ok := templateRef.Parameters[parameter.Name]; ok {
+			values[parameter.Name] = value
+		} else if parameter.Default != nil {
+			values[parameter.Name] = *parameter.Default
+		} else {
+			allErrs = append(allErrs, field.Required(fldPath.Key(parameter.Name), fmt.Sprintf("template %s has no default for it", template.Name)))
+		}
+	}
+
+	// walk the parameters in a stable order so that the errors are reported in the same order every time
+	names := make([]string, 0, len(templateRef.Parameters))
+	for name := range templateRef.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !slices.Contains(declaredNames, name) {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Key(name), name, declaredNames))
+		}
+	}
+	return values, allErrs
+}
+
+// renderTemplateSpec replaces the references to the parameters in the strings of the template spec. The spec is
+// rendered in its json form, where every reference is inside a string, and the values are escaped for json.
+func renderTemplateSpec(template *operatorv1alpha1.ZerokProbeTemplate, values map[string]string, fldPath *field.Path) (*operatorv1alpha1.ZerokProbeTemplateSpec, field.ErrorList) {
+	templateSpec := template.Spec.DeepCopy()
+	templateSpec.Parameters = nil
+	specJson, err := json.Marshal(templateSpec)
+	if err != nil {
+		return nil, field.ErrorList{field.InternalError(fldPath, err)}
+	}
+
+	allErrs := field.ErrorList{}
+	renderedJson := templateParameterPattern.ReplaceAllFunc(specJson, func(reference []byte) []byte {
+		name := string(templateParameterPattern.FindSubmatch(reference)[1])
+		value, ok := values[name]
+		if !ok {
+			allErrs = append(allErrs, field.Invalid(fldPath, template.Name, fmt.Sprintf("template refers to the undeclared parameter %q", name)))
+			return reference
+		}
+		escapedValue, _ := json.Marshal(value)
+		return escapedValue[1 : len(escapedValue)-1]
+	})
+	if len(allErrs) > 0 {
+		return nil, allErrs
+	}
+
+	renderedSpec := &operatorv1alpha1.ZerokProbeTemplateSpec{}
+	if err := json.Unmarshal(renderedJson, renderedSpec); err != nil {
+		return nil, field.ErrorList{field.InternalError(fldPath, err)}
+	}
+	return renderedSpec, nil
+}
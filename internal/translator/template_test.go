@@ -0,0 +1,148 @@
+package translator_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	operatorv1alpha1 "github.com/zerok-ai/zk-operator/api/v1alpha1"
+	"github.com/zerok-ai/zk-operator/internal/translator"
+	"github.com/zerok-ai/zk-utils-go/scenario/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// thresholdRule matches the spans whose status code is at least the value.
+func thresholdRule(value string) model.Rule {
+	id := `attributes."http.status_code"`
+	datatype := model.DataType(operatorv1alpha1.DataTypeInteger)
+	operator := model.OperatorTypes(operatorv1alpha1.OperatorGreaterThanEqual)
+	valueType := model.ValueTypes(value)
+	condition := model.AND
+	return model.Rule{Type: model.RULE_GROUP, RuleGroup: &model.RuleGroup{Condition: &condition, Rules: model.Rules{
+		{Type: model.RULE, RuleLeaf: &model.RuleLeaf{ID: &id, Datatype: &datatype, Operator: &operator, Value: &valueType}},
+	}}}
+}
+
+// newTemplate returns a template of the errors of a service, the threshold defaults to 500.
+func newTemplate() *operatorv1alpha1.ZerokProbeTemplate {
+	threshold := "500"
+	return &operatorv1alpha1.ZerokProbeTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "service-errors"},
+		Spec: operatorv1alpha1.ZerokProbeTemplateSpec{
+			Parameters: []operatorv1alpha1.ZerokProbeTemplateParameter{
+				{Name: "service"},
+				{Name: "threshold", Default: &threshold},
+			},
+			WorkloadScope: operatorv1alpha1.WorkloadScopeNamespace,
+			Workloads: operatorv1alpha1.Workloads{"OTEL/${service}": {
+				TraceRole: "server",
+				Rule:      thresholdRule("${threshold}"),
+			}},
+			RateLimit: []operatorv1alpha1.RateLimit{{BucketMaxSize: 5, BucketRefillSize: 5, TickDuration: "1m"}},
+		},
+	}
+}
+
+func newTemplatedProbe(parameters map[string]string) *operatorv1alpha1.ZerokProbe {
+	return &operatorv1alpha1.ZerokProbe{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders-errors", Namespace: "team-a"},
+		Spec: operatorv1alpha1.ZerokProbeSpec{
+			Title:    "orders errors",
+			Enabled:  true,
+			Template: &operatorv1alpha1.ZerokProbeTemplateRef{Name: "service-errors", Parameters: parameters},
+		},
+	}
+}
+
+func TestRenderZerokProbe(t *testing.T) {
+	undeclaredTemplate := newTemplate()
+	undeclaredTemplate.Spec.RateLimit[0].TickDuration = "${tick}"
+
+	tests := []struct {
+		name       string
+		parameters map[string]string
+		template   *operatorv1alpha1.ZerokProbeTemplate
+		// workloadKey and value are the key and rule value of the rendered workload
+		workloadKey string
+		value       string
+		// errs are the type and field of the expected errors
+		errs []string
+	}{
+		{name: "parameters set", parameters: map[string]string{"service": "orders", "threshold": "400"},
+			template: newTemplate(), workloadKey: "OTEL/orders", value: "400"},
+		{name: "default of a parameter", parameters: map[string]string{"service": "orders"},
+			template: newTemplate(), workloadKey: "OTEL/orders", value: "500"},
+		{name: "value escaped for json", parameters: map[string]string{"service": "orders", "threshold": `4"00`},
+			template: newTemplate(), workloadKey: "OTEL/orders", value: `4"00`},
+		{name: "parameter without a default", parameters: map[string]string{"threshold": "400"},
+			template: newTemplate(), errs: []string{"FieldValueRequired spec.template.parameters[service]"}},
+		{name: "parameter not declared", parameters: map[string]string{"service": "orders", "team": "a", "env": "prod"},
+			template: newTemplate(), errs: []string{
+				"FieldValueNotSupported spec.template.parameters[env]",
+				"FieldValueNotSupported spec.template.parameters[team]",
+			}},
+		{name: "template refers to an undeclared parameter", parameters: map[string]string{"service": "orders"},
+			template: undeclaredTemplate, errs: []string{"FieldValueInvalid spec.template.name"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe := newTemplatedProbe(tt.parameters)
+			rendered, err := translator.RenderZerokProbe(probe, tt.template)
+
+			if tt.errs != nil {
+				var translationErr *translator.TranslationError
+				if !errors.As(err, &translationErr) {
+					t.Fatalf("expected a TranslationError, got %v", err)
+				}
+				errs := make([]string, 0, len(translationErr.Errs))
+				for _, e := range translationErr.Errs {
+					errs = append(errs, string(e.Type)+" "+e.Field)
+				}
+				if !reflect.DeepEqual(errs, tt.errs) {
+					t.Fatalf("expected errors %v, got %v", tt.errs, errs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			spec := rendered.GetSpec()
+			if spec.Template != nil {
+				t.Errorf("expected the rendered probe not to refer to the template")
+			}
+			if spec.Title != "orders errors" || rendered.GetName() != "orders-errors" {
+				t.Errorf("expected the title and name of the probe to be kept, got %q and %q", spec.Title, rendered.GetName())
+			}
+			if spec.WorkloadScope != operatorv1alpha1.WorkloadScopeNamespace || len(spec.RateLimit) != 1 {
+				t.Errorf("expected the workload scope and rate limit of the template, got %q and %v", spec.WorkloadScope, spec.RateLimit)
+			}
+			workload, ok := spec.Workloads[tt.workloadKey]
+			if !ok || len(spec.Workloads) != 1 {
+				t.Fatalf("expected workload %s, got %v", tt.workloadKey, spec.Workloads)
+			}
+			if !reflect.DeepEqual(workload.Rule, thresholdRule(tt.value)) {
+				t.Errorf("expected the rule with value %q, got %+v", tt.value, workload.Rule.RuleGroup.Rules[0].RuleLeaf)
+			}
+			if probe.Spec.Template == nil || probe.Spec.Workloads != nil {
+				t.Errorf("expected the probe itself not to be changed")
+			}
+		})
+	}
+}
+
+func TestRenderZerokProbeWithoutTemplate(t *testing.T) {
+	probe := newSpecProbe(t, `
+title: errors
+enabled: true
+workloads:
+  OTEL/orders: {rule: `+specRule+`}
+`)
+	rendered, err := translator.RenderZerokProbe(probe, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rendered != operatorv1alpha1.Probe(probe) {
+		t.Errorf("expected the probe to be returned as it is")
+	}
+}
@@ -0,0 +1,325 @@
+package translator
+
+import (
+	"fmt"
+	operatorv1alpha1 "github.com/zerok-ai/zk-operator/api/v1alpha1"
+	"github.com/zerok-ai/zk-utils-go/scenario/model"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ScenarioTypeSystem is the type of the scenarios written by the operator, for probes of either kind.
+const ScenarioTypeSystem = "SYSTEM"
+
+// ClusterScenarioIdPrefix prefixes the id of the scenario of a ClusterZerokProbe, so that the id tells the kind of
+// the probe the scenario comes from. The scenario of a ZerokProbe keeps the uid of the probe as its id, which is
+// the key it was always stored under.
+const ClusterScenarioIdPrefix = "cluster-"
+
+// ScenarioId is the id the scenario of the probe is stored under.
+func ScenarioId(zerokProbe operatorv1alpha1.Probe) string {
+	if zerokProbe.GetProbeKind() == operatorv1alpha1.ClusterZerokProbeKind {
+		return ClusterScenarioIdPrefix + string(zerokProbe.GetUID())
+	}
+	return string(zerokProbe.GetUID())
+}
+
+// NamespaceAttributeId is the id of the rule which limits the OTEL workloads of a namespace scoped probe to the
+// spans of its namespace, the OpenTelemetry operator sets the k8s.namespace.name resource attribute on them.
+const NamespaceAttributeId = `resource_attributes."k8s.namespace.name"`
+
+// TranslationError is returned when a probe can not be translated into a scenario.
+// It aggregates all the errors found in the spec along with the path of the offending fields.
+type TranslationError struct {
+	Errs field.ErrorList
+}
+
+func (e *TranslationError) Error() string {
+	return e.Errs.ToAggregate().Error()
+}
+
+// NoWorkloadsSelectedError is returned for a valid probe whose workload selectors select no pods reporting a
+// service. The probe is inactive rather than invalid, it has a scenario again once matching pods appear.
+type NoWorkloadsSelectedError struct {
+	WorkloadKeys []string
+}
+
+func (e *NoWorkloadsSelectedError) Error() string {
+	return fmt.Sprintf("the selectors of workloads %s select no pods with an OTEL_SERVICE_NAME or a service.name resource attribute",
+		strings.Join(e.WorkloadKeys, ", "))
+}
+
+// WorkloadServices are the OTel service names the selectors of the workloads of a probe resolve to, by workload key.
+type WorkloadServices map[string][]string
+
+// TranslateZerokProbe converts a ZerokProbe or ClusterZerokProbe into the scenario stored in redis. The spec is
+// defaulted and validated first, a *TranslationError is returned with all the problems found in it. Whether the
+// operator allows probes in the namespace of the probe is up to the caller, see operatorv1alpha1.ValidateProbeNamespace.
+func TranslateZerokProbe(zerokProbe operatorv1alpha1.Probe) (model.Scenario, error) {
+	return TranslateZerokProbeWithServices(zerokProbe, nil)
+}
+
+// TranslateZerokProbeWithServices translates a probe whose workloads select their pods, with the services the
+// selectors resolve to. A workload with a selector is translated into one workload for each of its services, nil
+// services are reported as an error for each selector. A *NoWorkloadsSelectedError is returned when the probe is
+// otherwise valid but a selector resolves to no services.
+func TranslateZerokProbeWithServices(zerokProbe operatorv1alpha1.Probe, workloadServices WorkloadServices) (model.Scenario, error) {
+
+	if zerokProbe.GetSpec().Template != nil {
+		return model.Scenario{}, &TranslationError{Errs: field.ErrorList{
+			field.Forbidden(field.NewPath("spec", "template"), "a probe using a template has to be rendered from it before it is translated"),
+		}}
+	}
+
+	// the defaults are the same as the ones written by the defaulting webhook, so a probe translates to the same
+	// scenario whether the webhook is installed or not
+	spec := zerokProbe.GetSpec().DeepCopy()
+	operatorv1alpha1.SetZerokProbeSpecDefaults(spec, zerokProbe.GetNamespace())
+	// a single rule at the root is only rejected for new probes, existing ones keep working with the rule wrapped
+	for key, workload := range spec.Workloads {
+		workload.Rule = operatorv1alpha1.WrapRootRule(workload.Rule)
+		spec.Workloads[key] = workload
+	}
+
+	specPath := field.NewPath("spec")
+	allErrs := operatorv1alpha1.ValidateZerokProbeSpec(spec, zerokProbe.GetNamespace(), specPath)
+	if len(allErrs) > 0 {
+		return model.Scenario{}, &TranslationError{Errs: allErrs}
+	}
+
+	// the OTEL workloads of a namespace scoped probe only match the spans of its namespace
+	workloadNamespace := ""
+	if spec.WorkloadScope == operatorv1alpha1.WorkloadScopeNamespace {
+		workloadNamespace = zerokProbe.GetNamespace()
+	}
+	zerokProbeWorkloadsMap, zerokServiceWorkloadMap, errs := getZerokProbeWorkloadsFromCrd(spec.Workloads, workloadServices, workloadNamespace, specPath.Child("workloads"))
+	allErrs = append(allErrs, errs...)
+	rateLimit, errs := getZerokProbeRateLimitFromCrd(spec.RateLimit, specPath.Child("rate_limit"))
+	allErrs = append(allErrs, errs...)
+	filter, errs := getZerokProbeFiltersFromCrdFilters(spec.Filter, zerokServiceWorkloadMap, specPath.Child("filter"))
+	allErrs = append(allErrs, errs...)
+	groupBy, errs := getZerokProbeGroupByFromCrd(&spec.GroupBy, zerokServiceWorkloadMap, specPath.Child("group_by"))
+	allErrs = append(allErrs, errs...)
+	if len(allErrs) > 0 {
+		return model.Scenario{}, &TranslationError{Errs: allErrs}
+	}
+	if unselected := getUnselectedWorkloadKeys(spec.Workloads, workloadServices); len(unselected) > 0 {
+		return model.Scenario{}, &NoWorkloadsSelectedError{WorkloadKeys: unselected}
+	}
+
+	zkProbeScenario := model.Scenario{}
+	zkProbeScenario.Enabled = spec.Enabled
+	zkProbeScenario.Id = ScenarioId(zerokProbe)
+	zkProbeScenario.Title = spec.Title
+	zkProbeScenario.Type = ScenarioTypeSystem
+	zkProbeScenario.Workloads = &zerokProbeWorkloadsMap
+	zkProbeScenario.RateLimit = rateLimit
+	zkProbeScenario.Filter = filter
+	zkProbeScenario.GroupBy = groupBy
+	zkProbeScenario.Version = ScenarioVersion(zerokProbe.GetGeneration(), zkProbeScenario)
+	return zkProbeScenario, nil
+}
+
+// ScenarioVersion is the version of the scenario translated from the given generation of a probe, e.g. 3-5f1c2a9e.
+// It is the same for every translation of the same spec, and leads back to the revision of the probe it came from.
+func ScenarioVersion(generation int64, scenario model.Scenario) string {
+	return fmt.Sprintf("%d-%s", generation, ScenarioHash(scenario)[:scenarioVersionHashLength])
+}
+
+// getZerokProbeWorkloadsFromCrd returns the workloads of the scenario by their id, along with the ids of the workloads
+// each name in the workload keys is translated into. A name has more than one id when its selector resolves to
+// more than one service. The OTEL workloads are limited to the spans of namespace when it is not empty.
+func getZerokProbeWorkloadsFromCrd(crdWorkloadsMap map[string]operatorv1alpha1.Workload, workloadServices WorkloadServices, namespace string, fldPath *field.Path) (map[string]model.Workload, map[string][]string, field.ErrorList) {
+	allErrs := field.ErrorList{}
+	zerokProbeWorkloadsMap := make(map[string]model.Workload)
+	zerokServiceWorkloadMap := make(map[string][]string)
+	for key, value := range crdWorkloadsMap {
+		executor, serviceName, err := getExecutorAndServiceNameFromKey(key)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(key), key, err.Error()))
+			continue
+		}
+		services := []string{serviceName}
+		if executor == string(operatorv1alpha1.EBPF) {
+			// eBPF based collection identifies a workload by its namespace and deployment instead of a service name
+			services = []string{value.Namespace + "/" + value.Deployment}
+		} else if value.Selector != nil {
+			var selectorErr *field.Error
+			services, selectorErr = getSelectedServices(key, workloadServices, fldPath.Key(key).Child("selector"))
+			if selectorErr != nil {
+				allErrs = append(allErrs, selectorErr)
+			}
+			if len(services) == 0 {
+				// the workload is still known to the filter and group_by, so that only the selector is reported
+				zerokServiceWorkloadMap[serviceName] = nil
+				continue
+			}
+		}
+		rule := value.Rule
+		if executor != string(operatorv1alpha1.EBPF) && namespace != "" {
+			rule = scopeRuleToNamespace(rule, namespace)
+		}
+		for _, service := range services {
+			probeZerokWorkload := model.Workload{}
+			probeZerokWorkload.Service = service
+			probeZerokWorkload.Rule = *rule.DeepCopy()
+			probeZerokWorkload.TraceRole = model.TraceRole(value.TraceRole)
+			probeZerokWorkload.Protocol = model.ProtocolName(value.Protocol)
+			probeZerokWorkload.Executor = model.ExecutorName(executor)
+			workloadId := model.WorkLoadUUID(probeZerokWorkload).String()
+			zerokProbeWorkloadsMap[workloadId] = probeZerokWorkload
+			zerokServiceWorkloadMap[serviceName] = append(zerokServiceWorkloadMap[serviceName], workloadId)
+		}
+	}
+	return zerokProbeWorkloadsMap, zerokServiceWorkloadMap, allErrs
+}
+
+// scopeRuleToNamespace adds a rule on the namespace of the span to the root group of the rule. The collectors match
+// an OTEL workload by the plain service name, so the namespace can not be part of the service.
+func scopeRuleToNamespace(rule model.Rule, namespace string) model.Rule {
+	id := NamespaceAttributeId
+	datatype := model.DataType(operatorv1alpha1.DataTypeString)
+	operator := model.OperatorTypes(operatorv1alpha1.OperatorEqual)
+	value := model.ValueTypes(namespace)
+	namespaceRule := model.Rule{Type: model.RULE, RuleLeaf: &model.RuleLeaf{ID: &id, Datatype: &datatype, Operator: &operator, Value: &value}}
+
+	scopedRule := *rule.DeepCopy()
+	if scopedRule.RuleGroup != nil && scopedRule.RuleGroup.Condition != nil && *scopedRule.RuleGroup.Condition == model.AND {
+		scopedRule.RuleGroup.Rules = append(scopedRule.RuleGroup.Rules, namespaceRule)
+		return scopedRule
+	}
+	// the rules of an OR group are kept together in a group of their own
+	condition := model.AND
+	return model.Rule{Type: model.RULE_GROUP, RuleGroup: &model.RuleGroup{Condition: &condition, Rules: model.Rules{scopedRule, namespaceRule}}}
+}
+
+func getSelectedServices(workloadKey string, workloadServices WorkloadServices, fldPath *field.Path) ([]string, *field.Error) {
+	if workloadServices == nil {
+		return nil, field.Forbidden(fldPath, "a selector has to be resolved in the cluster before the probe is translated")
+	}
+	return workloadServices[workloadKey], nil
+}
+
+// getUnselectedWorkloadKeys returns the sorted keys of the workloads whose selector resolves to no services.
+func getUnselectedWorkloadKeys(crdWorkloadsMap map[string]operatorv1alpha1.Workload, workloadServices WorkloadServices) []string {
+	var unselected []string
+	for key, workload := range crdWorkloadsMap {
+		if workload.Selector != nil && len(workloadServices[key]) == 0 {
+			unselected = append(unselected, key)
+		}
+	}
+	sort.Strings(unselected)
+	return unselected
+}
+
+func getExecutorAndServiceNameFromKey(workloadKey string) (string, string, error) {
+	executor, serviceName, err := operatorv1alpha1.ParseWorkloadKey(workloadKey)
+	if err != nil {
+		return "", "", err
+	}
+	return string(executor), serviceName, nil
+}
+
+func getZerokProbeRateLimitFromCrd(crdRateLimitList []operatorv1alpha1.RateLimit, fldPath *field.Path) ([]model.RateLimit, field.ErrorList) {
+	if crdRateLimitList == nil {
+		return []model.RateLimit{
+			{BucketMaxSize: operatorv1alpha1.DefaultBucketMaxSize, BucketRefillSize: operatorv1alpha1.DefaultBucketRefillSize, TickDuration: operatorv1alpha1.DefaultTickDuration},
+		}, nil
+	}
+	allErrs := field.ErrorList{}
+	probeZerokRateLimitList := make([]model.RateLimit, 0)
+	for i, crdRateLimit := range crdRateLimitList {
+		tickDuration, err := time.ParseDuration(crdRateLimit.TickDuration)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("tick_duration"), crdRateLimit.TickDuration, err.Error()))
+			continue
+		}
+		// the collectors get the tick duration in its canonical form, however it is written in the probe
+		probeZerokRateLimitList = append(probeZerokRateLimitList, model.RateLimit{TickDuration: operatorv1alpha1.NormalizeDuration(tickDuration), BucketMaxSize: crdRateLimit.BucketMaxSize, BucketRefillSize: crdRateLimit.BucketRefillSize})
+	}
+	return probeZerokRateLimitList, allErrs
+}
+
+func getZerokProbeGroupByFromCrd(crdGroupByList *[]operatorv1alpha1.GroupBy, zerokServiceWorkloadMap map[string][]string, fldPath *field.Path) ([]model.GroupBy, field.ErrorList) {
+	if crdGroupByList == nil {
+		return nil, nil
+	}
+	allErrs := field.ErrorList{}
+	var probeZerokGroupByList []model.GroupBy
+	for i, crdGroupBy := range *crdGroupByList {
+		groupBy := &crdGroupBy
+		workloadIds, ok := zerokServiceWorkloadMap[groupBy.WorkloadKey]
+		if !ok {
+			allErrs = append(allErrs, field.NotFound(fldPath.Index(i).Child("workload_key"), groupBy.WorkloadKey))
+			continue
+		}
+		// the traces of every service a selector resolves to are grouped the same way
+		for _, workloadId := range workloadIds {
+			probeZerokGroupByList = append(probeZerokGroupByList, model.GroupBy{WorkloadId: workloadId, Title: groupBy.Title, Hash: groupBy.Hash})
+		}
+	}
+	return probeZerokGroupByList, allErrs
+}
+
+func getZerokProbeFiltersFromCrdFilters(crdFilter operatorv1alpha1.Filter, zerokServiceWorkloadMap map[string][]string, fldPath *field.Path) (model.Filter, field.ErrorList) {
+	var workloadIdList model.WorkloadIds
+	var probeZerokFilter model.Filter
+	if crdFilter.WorkloadKeys == nil && crdFilter.Filters == nil {
+		serviceNames := make([]string, 0, len(zerokServiceWorkloadMap))
+		for serviceName := range zerokServiceWorkloadMap {
+			serviceNames = append(serviceNames, serviceName)
+		}
+		sort.Strings(serviceNames)
+		workloadKeys := operatorv1alpha1.WorkloadKeys(serviceNames)
+		crdFilter = operatorv1alpha1.Filter{Type: "workload", Condition: "AND", WorkloadKeys: &workloadKeys}
+	}
+	allErrs := field.ErrorList{}
+	var newFilters model.Filters
+	//iterate over the services in filter and update them with workload id
+	// Check if WorkloadIds is not nil before iterating
+	if crdFilter.WorkloadKeys != nil {
+		// Iterate over WorkloadIds
+		for i, serviceId := range *crdFilter.WorkloadKeys {
+			workloadIds, ok := zerokServiceWorkloadMap[serviceId]
+			if !ok {
+				allErrs = append(allErrs, field.NotFound(fldPath.Child("workload_keys").Index(i), serviceId))
+				continue
+			}
+			if len(workloadIds) == 1 {
+				workloadIdList = append(workloadIdList, workloadIds[0])
+				continue
+			}
+			// a workload whose selector resolves to several services is satisfied by any of them
+			anyWorkloadIds := model.WorkloadIds(workloadIds)
+			newFilters = append(newFilters, model.Filter{Type: "workload", Condition: "OR", WorkloadIds: &anyWorkloadIds})
+		}
+		probeZerokFilter.WorkloadIds = &workloadIdList
+	}
+	if crdFilter.Filters != nil {
+		for i, filter := range *crdFilter.Filters {
+			newFilter, errs := getZerokProbeFiltersFromCrdFilters(filter, zerokServiceWorkloadMap, fldPath.Child("filters").Index(i))
+			allErrs = append(allErrs, errs...)
+			newFilters = append(newFilters, newFilter)
+		}
+	}
+	if newFilters != nil {
+		probeZerokFilter.Filters = &newFilters
+	}
+	if crdFilter.Type != "" {
+		probeZerokFilter.Type = crdFilter.Type
+	} else {
+		probeZerokFilter.Type = "workload"
+	}
+
+	switch crdFilter.Condition {
+	case "":
+		probeZerokFilter.Condition = "AND"
+	case operatorv1alpha1.AND, operatorv1alpha1.OR:
+		probeZerokFilter.Condition = model.Condition(crdFilter.Condition)
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("condition"), crdFilter.Condition, []string{string(operatorv1alpha1.AND), string(operatorv1alpha1.OR)}))
+	}
+	return probeZerokFilter, allErrs
+}
@@ -0,0 +1,337 @@
+package translator_test
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	operatorv1alpha1 "github.com/zerok-ai/zk-operator/api/v1alpha1"
+	"github.com/zerok-ai/zk-operator/internal/evaluator"
+	"github.com/zerok-ai/zk-operator/internal/translator"
+	"github.com/zerok-ai/zk-utils-go/scenario/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func statusRule(condition model.Condition) model.Rule {
+	id := `attributes."http.status_code"`
+	datatype := model.DataType(operatorv1alpha1.DataTypeInteger)
+	operator := model.OperatorTypes(operatorv1alpha1.OperatorGreaterThanEqual)
+	value := model.ValueTypes("400")
+	return model.Rule{Type: model.RULE_GROUP, RuleGroup: &model.RuleGroup{Condition: &condition, Rules: model.Rules{
+		{Type: model.RULE, RuleLeaf: &model.RuleLeaf{ID: &id, Datatype: &datatype, Operator: &operator, Value: &value}},
+	}}}
+}
+
+func newProbe(workloadScope operatorv1alpha1.WorkloadScope, rule model.Rule) *operatorv1alpha1.ZerokProbe {
+	return &operatorv1alpha1.ZerokProbe{
+		ObjectMeta: metav1.ObjectMeta{Name: "errors", Namespace: "team-a", UID: "probe-uid", Generation: 1},
+		Spec: operatorv1alpha1.ZerokProbeSpec{
+			Title:         "errors",
+			Enabled:       true,
+			WorkloadScope: workloadScope,
+			Workloads:     map[string]operatorv1alpha1.Workload{"OTEL/orders": {Rule: rule}},
+		},
+	}
+}
+
+func span(namespace string) evaluator.Span {
+	return evaluator.Span{
+		TraceId:            "trace-" + namespace,
+		SpanId:             "span-" + namespace,
+		Service:            "orders",
+		Kind:               "server",
+		Attributes:         map[string]interface{}{"http.status_code": 404},
+		ResourceAttributes: map[string]interface{}{"k8s.namespace.name": namespace},
+	}
+}
+
+func TestTranslateNamespaceScopedOtelWorkload(t *testing.T) {
+	tests := []struct {
+		name          string
+		workloadScope operatorv1alpha1.WorkloadScope
+		condition     model.Condition
+		// matches tells whether the traces of orders in team-a and team-b pass the filter
+		matches map[string]bool
+	}{
+		{name: "cluster scope", workloadScope: operatorv1alpha1.WorkloadScopeCluster, condition: model.AND,
+			matches: map[string]bool{"team-a": true, "team-b": true}},
+		{name: "namespace scope", workloadScope: operatorv1alpha1.WorkloadScopeNamespace, condition: model.AND,
+			matches: map[string]bool{"team-a": true, "team-b": false}},
+		{name: "namespace scope with an OR rule group", workloadScope: operatorv1alpha1.WorkloadScopeNamespace, condition: model.OR,
+			matches: map[string]bool{"team-a": true, "team-b": false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scenario, err := translator.TranslateZerokProbe(newProbe(tt.workloadScope, statusRule(tt.condition)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(*scenario.Workloads) != 1 {
+				t.Fatalf("expected 1 workload, got %d", len(*scenario.Workloads))
+			}
+			for _, workload := range *scenario.Workloads {
+				// the collectors match OTEL workloads on the plain service.name of the span
+				if workload.Service != "orders" {
+					t.Errorf("expected service orders, got %q", workload.Service)
+				}
+				if workload.Executor != model.ExecutorOTel {
+					t.Errorf("expected executor OTEL, got %q", workload.Executor)
+				}
+			}
+
+			traces := []evaluator.Trace{
+				{TraceId: "trace-team-a", Spans: []evaluator.Span{span("team-a")}},
+				{TraceId: "trace-team-b", Spans: []evaluator.Span{span("team-b")}},
+			}
+			result := evaluator.Evaluate(scenario, traces)
+			for i, namespace := range []string{"team-a", "team-b"} {
+				if result.Traces[i].PassesFilter != tt.matches[namespace] {
+					t.Errorf("trace of %s: expected passes_filter %v, got %v", namespace, tt.matches[namespace], result.Traces[i].PassesFilter)
+				}
+			}
+		})
+	}
+}
+
+func TestTranslateNamespaceScopeDoesNotChangeTheProbe(t *testing.T) {
+	probe := newProbe(operatorv1alpha1.WorkloadScopeNamespace, statusRule(model.AND))
+	if _, err := translator.TranslateZerokProbe(probe); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rules := probe.Spec.Workloads["OTEL/orders"].Rule.RuleGroup.Rules; len(rules) != 1 {
+		t.Errorf("expected the rule of the probe to keep 1 rule, got %d", len(rules))
+	}
+}
+
+func TestTranslateWorkloadSelectors(t *testing.T) {
+	tests := []struct {
+		name             string
+		workloadServices translator.WorkloadServices
+		workloadScope    operatorv1alpha1.WorkloadScope
+		// services are the services of the translated workloads, nil when the probe is not translated
+		services          []string
+		noWorkloadsErr    bool
+		translationErrors int
+	}{
+		{name: "selector resolved", workloadServices: translator.WorkloadServices{"OTEL/orders": {"orders", "orders-v2"}},
+			workloadScope: operatorv1alpha1.WorkloadScopeCluster, services: []string{"orders", "orders-v2"}},
+		{name: "selector selects no pods", workloadServices: translator.WorkloadServices{},
+			workloadScope: operatorv1alpha1.WorkloadScopeCluster, noWorkloadsErr: true},
+		{name: "selector selects no pods in an invalid probe", workloadServices: translator.WorkloadServices{},
+			workloadScope: "Everywhere", translationErrors: 1},
+		{name: "selector not resolved", workloadServices: nil,
+			workloadScope: operatorv1alpha1.WorkloadScopeCluster, translationErrors: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe := newProbe(tt.workloadScope, statusRule(model.AND))
+			workload := probe.Spec.Workloads["OTEL/orders"]
+			workload.Selector = &operatorv1alpha1.WorkloadSelector{Deployment: "orders"}
+			probe.Spec.Workloads["OTEL/orders"] = workload
+
+			scenario, err := translator.TranslateZerokProbeWithServices(probe, tt.workloadServices)
+
+			var noWorkloadsErr *translator.NoWorkloadsSelectedError
+			if errors.As(err, &noWorkloadsErr) != tt.noWorkloadsErr {
+				t.Fatalf("expected a NoWorkloadsSelectedError %v, got %v", tt.noWorkloadsErr, err)
+			}
+			var translationErr *translator.TranslationError
+			if errors.As(err, &translationErr) && len(translationErr.Errs) != tt.translationErrors {
+				t.Fatalf("expected %d translation errors, got %v", tt.translationErrors, err)
+			}
+			if tt.services == nil {
+				if err == nil {
+					t.Fatalf("expected an error, got scenario %v", scenario)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var services []string
+			for _, workload := range *scenario.Workloads {
+				services = append(services, workload.Service)
+			}
+			sort.Strings(services)
+			if !reflect.DeepEqual(services, tt.services) {
+				t.Errorf("expected services %v, got %v", tt.services, services)
+			}
+		})
+	}
+}
+
+const specRule = `{type: rule_group, condition: AND, rules: [{type: rule, id: http.status_code, datatype: integer, operator: greater_than_equal, value: "400"}]}`
+
+// newSpecProbe returns a probe with the spec read from yaml.
+func newSpecProbe(t *testing.T, spec string) *operatorv1alpha1.ZerokProbe {
+	probe := &operatorv1alpha1.ZerokProbe{ObjectMeta: metav1.ObjectMeta{Name: "errors", Namespace: "team-a", UID: "probe-uid", Generation: 1}}
+	if err := yaml.UnmarshalStrict([]byte(spec), &probe.Spec); err != nil {
+		t.Fatalf("invalid spec: %v", err)
+	}
+	return probe
+}
+
+// describeFilter writes the filter with the services of its workloads instead of their ids, e.g. AND(orders,OR(cart)).
+func describeFilter(filter model.Filter, workloads map[string]model.Workload) string {
+	var parts []string
+	if filter.WorkloadIds != nil {
+		for _, workloadId := range *filter.WorkloadIds {
+			parts = append(parts, workloads[workloadId].Service)
+		}
+	}
+	if filter.Filters != nil {
+		for _, nestedFilter := range *filter.Filters {
+			parts = append(parts, describeFilter(nestedFilter, workloads))
+		}
+	}
+	return string(filter.Condition) + "(" + strings.Join(parts, ",") + ")"
+}
+
+func TestTranslateZerokProbeSpec(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		// filter is the filter of the scenario as written by describeFilter
+		filter    string
+		groupBy   []string
+		rateLimit []model.RateLimit
+		// errs are the type and field of the expected errors, the probe is not translated when set
+		errs []string
+	}{
+		{name: "filter derived from the workloads", spec: `
+title: errors
+enabled: true
+workloads:
+  OTEL/orders: {rule: ` + specRule + `}
+  OTEL/cart: {rule: ` + specRule + `}
+`, filter: "AND(cart,orders)",
+			rateLimit: []model.RateLimit{{BucketMaxSize: 5, BucketRefillSize: 5, TickDuration: "1m"}}},
+		{name: "nested filter", spec: `
+title: errors
+enabled: true
+workloads:
+  OTEL/orders: {rule: ` + specRule + `}
+  OTEL/cart: {rule: ` + specRule + `}
+  OTEL/payments: {rule: ` + specRule + `}
+filter:
+  type: workload
+  condition: AND
+  workload_keys: [orders]
+  filters:
+    - {type: workload, condition: OR, workload_keys: [cart, payments]}
+`, filter: "AND(orders,OR(cart,payments))",
+			rateLimit: []model.RateLimit{{BucketMaxSize: 5, BucketRefillSize: 5, TickDuration: "1m"}}},
+		{name: "group by and rate limits", spec: `
+title: errors
+enabled: true
+workloads:
+  OTEL/orders: {rule: ` + specRule + `}
+  EBPF/payments: {namespace: shop, rule: ` + specRule + `}
+group_by:
+  - {workload_key: orders, title: attributes."http.route", hash: attributes."http.route"}
+  - {workload_key: payments, title: attributes."http.method", hash: attributes."http.method"}
+rate_limit:
+  - {bucket_max_size: 10, bucket_refill_size: 10, tick_duration: 60s}
+  - {bucket_max_size: 100, bucket_refill_size: 50, tick_duration: 1h}
+`, filter: "AND(orders,shop/payments)", groupBy: []string{"orders", "shop/payments"},
+			rateLimit: []model.RateLimit{
+				{BucketMaxSize: 10, BucketRefillSize: 10, TickDuration: "1m"},
+				{BucketMaxSize: 100, BucketRefillSize: 50, TickDuration: "1h"},
+			}},
+		{name: "errors of the spec are reported together", spec: `
+title: errors
+enabled: true
+workloads:
+  OTEL/orders: {rule: ` + specRule + `}
+filter:
+  type: workload
+  condition: AND
+  workload_keys: [orders, cart]
+group_by:
+  - {workload_key: payments, title: t, hash: h}
+rate_limit:
+  - {bucket_max_size: 10, bucket_refill_size: 10, tick_duration: 60s}
+  - {bucket_max_size: 10, bucket_refill_size: 10, tick_duration: 1m}
+`, errs: []string{
+			"FieldValueNotFound spec.filter.workload_keys[1]",
+			"FieldValueNotFound spec.group_by[0].workload_key",
+			"FieldValueDuplicate spec.rate_limit[1].tick_duration",
+		}},
+		{name: "single rule at the root", spec: `
+title: errors
+enabled: true
+workloads:
+  OTEL/orders:
+    rule: {type: rule, id: http.status_code, datatype: integer, operator: greater_than_equal, value: "400"}
+`, filter: "AND(orders)",
+			rateLimit: []model.RateLimit{{BucketMaxSize: 5, BucketRefillSize: 5, TickDuration: "1m"}}},
+		{name: "probe referring to a template", spec: `
+title: errors
+enabled: true
+template: {name: service-errors}
+`, errs: []string{"FieldValueForbidden spec.template"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scenario, err := translator.TranslateZerokProbe(newSpecProbe(t, tt.spec))
+
+			if tt.errs != nil {
+				var translationErr *translator.TranslationError
+				if !errors.As(err, &translationErr) {
+					t.Fatalf("expected a TranslationError, got %v", err)
+				}
+				errs := make([]string, 0, len(translationErr.Errs))
+				for _, e := range translationErr.Errs {
+					errs = append(errs, string(e.Type)+" "+e.Field)
+				}
+				if !reflect.DeepEqual(errs, tt.errs) {
+					t.Fatalf("expected errors %v, got %v", tt.errs, errs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if filter := describeFilter(scenario.Filter, *scenario.Workloads); filter != tt.filter {
+				t.Errorf("expected filter %s, got %s", tt.filter, filter)
+			}
+			var groupBy []string
+			for _, g := range scenario.GroupBy {
+				groupBy = append(groupBy, (*scenario.Workloads)[g.WorkloadId].Service)
+			}
+			if !reflect.DeepEqual(groupBy, tt.groupBy) {
+				t.Errorf("expected group_by on %v, got %v", tt.groupBy, groupBy)
+			}
+			if !reflect.DeepEqual(scenario.RateLimit, tt.rateLimit) {
+				t.Errorf("expected rate limits %v, got %v", tt.rateLimit, scenario.RateLimit)
+			}
+			if scenario.Id != "probe-uid" || scenario.Type != translator.ScenarioTypeSystem {
+				t.Errorf("expected the id and type of the probe, got %q and %q", scenario.Id, scenario.Type)
+			}
+		})
+	}
+}
+
+func TestTranslateClusterZerokProbe(t *testing.T) {
+	zerokProbe := newProbe(operatorv1alpha1.WorkloadScopeCluster, statusRule(model.AND))
+	clusterProbe := &operatorv1alpha1.ClusterZerokProbe{
+		ObjectMeta: metav1.ObjectMeta{Name: "errors", UID: "probe-uid", Generation: 1},
+		Spec:       zerokProbe.Spec,
+	}
+
+	scenario, err := translator.TranslateZerokProbe(clusterProbe)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if scenario.Id != "cluster-probe-uid" || scenario.Type != translator.ScenarioTypeSystem {
+		t.Errorf("expected the kind in the id and the SYSTEM type, got %q and %q", scenario.Id, scenario.Type)
+	}
+	if id := translator.ScenarioId(zerokProbe); id != "probe-uid" {
+		t.Errorf("expected the scenario of a ZerokProbe to keep its uid as id, got %q", id)
+	}
+}
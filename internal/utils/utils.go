@@ -5,5 +5,9 @@ import (
 )
 
 func GetTypeName(i interface{}) string {
-	return reflect.TypeOf(i).Name()
+	t := reflect.TypeOf(i)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
 }
@@ -3,4 +3,6 @@ package internal
 type ZkOperatorModule interface {
 	CleanUpOnKill() error
 	IsHealthy() bool
+	// LastError is the error of the last failed health check, nil when the last check passed.
+	LastError() error
 }
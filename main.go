@@ -8,7 +8,10 @@ import (
 	"flag"
 	"fmt"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"strings"
 	"time"
 
 	"github.com/zerok-ai/zk-operator/internal"
@@ -23,6 +26,8 @@ import (
 	operatorv1alpha1 "github.com/zerok-ai/zk-operator/api/v1alpha1"
 	"github.com/zerok-ai/zk-operator/controllers"
 	handler "github.com/zerok-ai/zk-operator/internal/handler"
+	"github.com/zerok-ai/zk-operator/internal/lifecycle"
+	promMetrics "github.com/zerok-ai/zk-operator/internal/metrics"
 	server "github.com/zerok-ai/zk-operator/internal/server"
 
 	"github.com/ilyakaznacheev/cleanenv"
@@ -48,8 +53,13 @@ func init() {
 func main() {
 	var metricsAddr string
 	var probeAddr string
+	var enableLeaderElection bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
+		"Enable leader election for controller manager. "+
+			"Enabling this will ensure there is only one active controller manager. "+
+			"It can also be enabled with leaderElection.enabled in the operator config.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -61,26 +71,53 @@ func main() {
 	var d time.Duration = 15 * time.Minute
 
 	setupLog.Info("Starting Operator.")
-	zkCRDProbeHandler, err := initOperator()
+	zkConfig, zkCRDProbeHandler, err := initOperator()
 	if err != nil {
 		message := "Failed to initialize operator with error " + err.Error()
 		setupLog.Info(message)
 		return
 	}
 
+	drainTimeout := time.Duration(zkConfig.Shutdown.DrainTimeout) * time.Second
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
-		Port:                   9443,
+		Port:                   zkConfig.Webhook.Port,
+		CertDir:                zkConfig.Webhook.CertDir,
 		HealthProbeBindAddress: probeAddr,
 		Namespace:              "",
 		SyncPeriod:             &d,
+		// only the leader reconciles the probes and writes the scenarios, every replica serves the http api
+		LeaderElection:          enableLeaderElection || zkConfig.LeaderElection.Enabled,
+		LeaderElectionID:        zkConfig.LeaderElection.Id,
+		LeaderElectionNamespace: zkConfig.LeaderElection.Namespace,
+		// the process exits right after the manager stops, so the lease can be handed over without waiting for it to expire
+		LeaderElectionReleaseOnCancel: true,
+		GracefulShutdownTimeout:       &drainTimeout,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		panic("unable to start manager")
 	}
 
+	// the templates and the workloads selected by the probes are read from the cache of the manager, which also feeds
+	// the watches of the controllers on them
+	zkCRDProbeHandler.TemplateReader = mgr.GetClient()
+	zkCRDProbeHandler.WorkloadReader = mgr.GetClient()
+
+	zkModules := make([]internal.ZkOperatorModule, 0)
+
+	//Adding crdProbeHandler to zkModules
+	zkModules = append(zkModules, zkCRDProbeHandler)
+
+	// the probe api lists the probes from the cache of the manager as well, the controllers already watch both kinds
+	app := startHttpServer(zkConfig, zkModules, zkCRDProbeHandler, mgr.GetClient())
+	operatorLifecycle := lifecycle.Lifecycle{
+		App:                 app,
+		Modules:             zkModules,
+		HttpShutdownTimeout: time.Duration(zkConfig.Shutdown.HttpTimeout) * time.Second,
+	}
+
 	if err = (&controllers.ZerokProbeReconciler{
 		Client:            mgr.GetClient(),
 		Scheme:            mgr.GetScheme(),
@@ -90,8 +127,44 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "ZerokProbe")
 		panic("unable to create controller")
 	}
+	if err = (&controllers.ClusterZerokProbeReconciler{
+		ZerokProbeReconciler: controllers.ZerokProbeReconciler{
+			Client:            mgr.GetClient(),
+			Scheme:            mgr.GetScheme(),
+			ZkCRDProbeHandler: zkCRDProbeHandler,
+			Recorder:          mgr.GetEventRecorderFor("cluster-zerok-probe-controller"),
+		},
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterZerokProbe")
+		panic("unable to create controller")
+	}
+	if zkConfig.DriftDetection.Enabled {
+		if err = (&controllers.ZerokProbeDriftDetector{
+			Client:            mgr.GetClient(),
+			ZkCRDProbeHandler: zkCRDProbeHandler,
+			Interval:          time.Duration(zkConfig.DriftDetection.Interval) * time.Second,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create drift detector", "controller", "ZerokProbe")
+			panic("unable to create drift detector")
+		}
+	}
+	if zkConfig.Webhook.Enabled {
+		if err = (&operatorv1alpha1.ZerokProbe{}).SetupWebhookWithManager(mgr, zkConfig.Probes.AllowedNamespaces); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ZerokProbe")
+			panic("unable to create webhook")
+		}
+		if err = (&operatorv1alpha1.ClusterZerokProbe{}).SetupWebhookWithManager(mgr, zkConfig.Probes.AllowedNamespaces); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ClusterZerokProbe")
+			panic("unable to create webhook")
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
+	if err := promMetrics.RegisterProbeCollector(mgr.GetClient()); err != nil {
+		setupLog.Error(err, "unable to register probe metrics")
+		panic("unable to register probe metrics")
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		panic("unable to set up health check")
@@ -100,62 +173,79 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		panic("unable to set up ready check")
 	}
+	if err := mgr.AddReadyzCheck("scenario-store", zkCRDProbeHandler.ReadyzCheck); err != nil {
+		setupLog.Error(err, "unable to set up scenario store ready check")
+		panic("unable to set up scenario store ready check")
+	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	// on SIGTERM the manager waits for the in-flight reconciles to finish before Start returns
+	err = mgr.Start(ctrl.SetupSignalHandler())
+	if shutdownErr := operatorLifecycle.Shutdown(); shutdownErr != nil {
+		setupLog.Error(shutdownErr, "problem shutting down operator")
+	}
+	if err != nil {
 		setupLog.Error(err, "problem running manager")
 		panic("problem running manager")
 	}
+	setupLog.Info("operator stopped")
 }
 
-func initOperator() (*handler.ZkCRDProbeHandler, error) {
+func initOperator() (*config.ZkOperatorConfig, *handler.ZkCRDProbeHandler, error) {
 
 	configPath := env.GetString("CONFIG_FILE", "")
 	if configPath == "" {
 		zklogger.Error(LOG_TAG, "Config yaml path not found.")
-		return nil, fmt.Errorf("config yaml path not found")
+		return nil, nil, fmt.Errorf("config yaml path not found")
 	}
 
 	var zkConfig config.ZkOperatorConfig
 
 	if err := cleanenv.ReadConfig(configPath, &zkConfig); err != nil {
 		zklogger.Error(LOG_TAG, "Error while reading config ", err)
-		return nil, err
+		return nil, nil, err
 	}
 
 	zklogger.Init(zkConfig.LogsConfig)
 
 	zklogger.Debug(LOG_TAG, "Successfully read configs.")
 
-	zkModules := make([]internal.ZkOperatorModule, 0)
-
 	crdProbeHandler := handler.ZkCRDProbeHandler{}
 	err := crdProbeHandler.Init(zkConfig)
 	if err != nil {
 		zklogger.Error(LOG_TAG, "Error while creating scenarioHandler ", err)
-		return nil, err
+		return nil, nil, err
 	}
 
-	//Adding crdProbeHandler to zkModules
-	zkModules = append(zkModules, &crdProbeHandler)
+	return &zkConfig, &crdProbeHandler, nil
+}
 
+// startHttpServer starts the http server of the operator in the background. The probes are read straight from the
+// api server, so the api works before the caches of the manager are synced. The server is shut down by the
+// lifecycle of the operator once the manager has stopped, instead of by the interrupt handler of iris.
+func startHttpServer(zkConfig *config.ZkOperatorConfig, zkModules []internal.ZkOperatorModule, crdProbeHandler *handler.ZkCRDProbeHandler, reader client.Reader) *iris.Application {
 	irisConfig := iris.WithConfiguration(iris.Configuration{
-		DisablePathCorrection: true,
-		LogLevel:              zkConfig.LogsConfig.Level,
+		DisablePathCorrection:   true,
+		DisableInterruptHandler: true,
+		LogLevel:                zkConfig.LogsConfig.Level,
 	})
 
 	app := newApp()
 
 	// start http server
-	go server.StartHttpServer(app, irisConfig, zkConfig, zkModules)
-
-	return &crdProbeHandler, nil
+	go server.StartHttpServer(app, irisConfig, *zkConfig, zkModules, reader, crdProbeHandler)
+	return app
 }
 
 func newApp() *iris.Application {
 	app := iris.Default()
 
 	crs := func(ctx iris.Context) {
+		// the probe api gets neither cors headers nor preflight answers, so browsers do not call it cross origin
+		if strings.HasPrefix(ctx.Path(), server.ProbeApiPath) {
+			ctx.Next()
+			return
+		}
 		ctx.Header("Access-Control-Allow-Credentials", "true")
 
 		if ctx.Method() == iris.MethodOptions {
@@ -177,7 +267,7 @@ func newApp() *iris.Application {
 	app.AllowMethods(iris.MethodOptions)
 
 	//scraping metrics for prometheus
-	app.Get("/metrics", iris.FromStd(promhttp.Handler()))
+	app.Get("/metrics", iris.FromStd(promhttp.HandlerFor(ctrlmetrics.Registry, promhttp.HandlerOpts{})))
 
 	return app
 }